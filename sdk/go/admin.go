@@ -0,0 +1,69 @@
+package barqgraphdb
+
+import (
+	"context"
+	"time"
+)
+
+// WarmUp asks the server to load its vector index into memory so the first
+// queries after startup don't pay for it. It returns how long warming took
+// if the server reports it, or zero otherwise.
+func (c *Client) WarmUp() (time.Duration, error) {
+	var result struct {
+		DurationMs float64 `json:"duration_ms"`
+	}
+	if err := c.doRequest("POST", "/admin/warmup", nil, &result); err != nil {
+		return 0, err
+	}
+	return time.Duration(result.DurationMs * float64(time.Millisecond)), nil
+}
+
+// IntegrityReport lists the integrity problems found by Validate.
+type IntegrityReport struct {
+	// DanglingEdges are edges whose source or target node does not exist.
+	DanglingEdges []Edge `json:"dangling_edges"`
+	// OrphanEmbeddings are IDs with a stored embedding but no node.
+	OrphanEmbeddings []uint64 `json:"orphan_embeddings"`
+	// DanglingDecisions are decisions whose root node or path references a
+	// node that does not exist.
+	DanglingDecisions []Decision `json:"dangling_decisions"`
+}
+
+// OK reports whether the report found no problems.
+func (r *IntegrityReport) OK() bool {
+	return len(r.DanglingEdges) == 0 && len(r.OrphanEmbeddings) == 0 && len(r.DanglingDecisions) == 0
+}
+
+// Validate asks the server to check the graph's integrity.
+func (c *Client) Validate() (*IntegrityReport, error) {
+	var report IntegrityReport
+	if err := c.doRequest("GET", "/admin/validate", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// LoadInfo describes how busy the server is.
+type LoadInfo struct {
+	// QueueDepth is the number of requests waiting to be processed.
+	QueueDepth int `json:"queue_depth"`
+	// ActiveQueries is the number of queries currently executing.
+	ActiveQueries int `json:"active_queries"`
+	// CPUPercent and MemoryPercent are the server's resource usage, 0-100.
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryPercent float64 `json:"memory_percent"`
+}
+
+// ServerLoad returns the server's current load, so clients doing heavy work
+// can slow down while it is busy. See Backpressure for BulkLoader.
+func (c *Client) ServerLoad() (*LoadInfo, error) {
+	return c.serverLoad(context.Background())
+}
+
+func (c *Client) serverLoad(ctx context.Context) (*LoadInfo, error) {
+	var load LoadInfo
+	if err := c.doRequestContext(ctx, "GET", "/admin/load", nil, &load); err != nil {
+		return nil, err
+	}
+	return &load, nil
+}
@@ -0,0 +1,100 @@
+package barqgraphdb
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWarmUp(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/admin/warmup" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"duration_ms":1250.5}`))
+	})
+
+	took, err := client.WarmUp()
+	if err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if took != 1250500*time.Microsecond {
+		t.Errorf("Expected 1.2505s, got %v", took)
+	}
+}
+
+func TestWarmUpWithoutTiming(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	took, err := client.WarmUp()
+	if err != nil {
+		t.Fatalf("WarmUp failed: %v", err)
+	}
+	if took != 0 {
+		t.Errorf("Expected no timing, got %v", took)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/admin/validate" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"dangling_edges": [{"from": 1, "to": 99, "edge_type": "OWNS"}],
+			"orphan_embeddings": [42, 43],
+			"dangling_decisions": [{"id": 5, "agent_id": 1, "root_node": 77, "path": [77], "score": 0.5}]
+		}`))
+	})
+
+	report, err := client.Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if report.OK() {
+		t.Error("Expected integrity problems")
+	}
+	if len(report.DanglingEdges) != 1 || report.DanglingEdges[0].To != 99 {
+		t.Errorf("unexpected dangling edges %+v", report.DanglingEdges)
+	}
+	if len(report.OrphanEmbeddings) != 2 || report.OrphanEmbeddings[1] != 43 {
+		t.Errorf("unexpected orphan embeddings %v", report.OrphanEmbeddings)
+	}
+	if len(report.DanglingDecisions) != 1 || report.DanglingDecisions[0].RootNode != 77 {
+		t.Errorf("unexpected dangling decisions %+v", report.DanglingDecisions)
+	}
+}
+
+func TestValidateClean(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"dangling_edges":[],"orphan_embeddings":[],"dangling_decisions":[]}`))
+	})
+
+	report, err := client.Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected a clean report, got %+v", report)
+	}
+}
+
+func TestServerLoad(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/admin/load" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"queue_depth":42,"active_queries":7,"cpu_percent":83.5,"memory_percent":61.25}`))
+	})
+
+	load, err := client.ServerLoad()
+	if err != nil {
+		t.Fatalf("ServerLoad failed: %v", err)
+	}
+	want := LoadInfo{QueueDepth: 42, ActiveQueries: 7, CPUPercent: 83.5, MemoryPercent: 61.25}
+	if *load != want {
+		t.Errorf("Expected %+v, got %+v", want, *load)
+	}
+}
@@ -0,0 +1,102 @@
+package barqgraphdb
+
+import "fmt"
+
+// DegreeCentrality returns each node's degree centrality, scaled so the
+// best-connected node scores 1.
+func (c *Client) DegreeCentrality() (map[uint64]float32, error) {
+	return c.centrality("degree")
+}
+
+// BetweennessCentrality returns each node's betweenness centrality, scaled
+// so the node lying on the most shortest paths scores 1.
+func (c *Client) BetweennessCentrality() (map[uint64]float32, error) {
+	return c.centrality("betweenness")
+}
+
+func (c *Client) centrality(kind string) (map[uint64]float32, error) {
+	var result struct {
+		Scores map[uint64]float32 `json:"scores"`
+	}
+	if err := c.doRequest("GET", "/algorithms/centrality/"+kind, nil, &result); err != nil {
+		return nil, err
+	}
+	return normalizeScores(result.Scores), nil
+}
+
+// normalizeScores scales non-negative scores into [0,1] by dividing by the
+// maximum. A nil map becomes an empty one; all-zero scores are unchanged.
+func normalizeScores(scores map[uint64]float32) map[uint64]float32 {
+	normalized := make(map[uint64]float32, len(scores))
+	var max float32
+	for _, s := range scores {
+		if s > max {
+			max = s
+		}
+	}
+	for id, s := range scores {
+		if max > 0 {
+			s /= max
+		}
+		normalized[id] = s
+	}
+	return normalized
+}
+
+// DetectCommunities partitions the graph into communities with the server's
+// Louvain-style algorithm, returning each node's community ID. Higher
+// resolution yields more, smaller communities; it must be positive.
+func (c *Client) DetectCommunities(resolution float32) (map[uint64]int, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("%w: resolution must be positive, got %v", ErrInvalidArgument, resolution)
+	}
+	payload := struct {
+		Resolution float32 `json:"resolution"`
+	}{
+		Resolution: resolution,
+	}
+	var result struct {
+		Communities map[uint64]int `json:"communities"`
+	}
+	if err := c.doRequest("POST", "/algorithms/communities", payload, &result); err != nil {
+		return nil, err
+	}
+	if result.Communities == nil {
+		result.Communities = map[uint64]int{}
+	}
+	return result.Communities, nil
+}
+
+// GraphMetrics summarizes the structure of the graph.
+type GraphMetrics struct {
+	NodeCount            int `json:"node_count"`
+	EdgeCount            int `json:"edge_count"`
+	LargestComponentSize int `json:"largest_component_size"`
+	// Density is the fraction of possible directed edges that exist.
+	Density float64 `json:"-"`
+	// AverageDegree is the mean number of edges (in and out) per node.
+	AverageDegree float64 `json:"-"`
+}
+
+// GraphMetrics returns node and edge counts, the size of the largest
+// connected component, and the density and average degree derived from
+// them. An empty graph has zero density and average degree.
+func (c *Client) GraphMetrics() (*GraphMetrics, error) {
+	var metrics GraphMetrics
+	if err := c.doRequest("GET", "/metrics", nil, &metrics); err != nil {
+		return nil, err
+	}
+	metrics.computeDerived()
+	return &metrics, nil
+}
+
+func (m *GraphMetrics) computeDerived() {
+	n := float64(m.NodeCount)
+	e := float64(m.EdgeCount)
+	if m.NodeCount > 0 {
+		m.AverageDegree = 2 * e / n
+	}
+	if m.NodeCount > 1 {
+		m.Density = e / (n * (n - 1))
+	}
+}
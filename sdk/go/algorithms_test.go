@@ -0,0 +1,127 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// centralityServer serves raw scores for a star graph with hub 1 and
+// leaves 2, 3 and 4, where leaf 4 also links to leaf 3.
+func centralityServer(t *testing.T) *Client {
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/algorithms/centrality/degree":
+			w.Write([]byte(`{"scores":{"1":3,"2":1,"3":2,"4":2}}`))
+		case "/algorithms/centrality/betweenness":
+			w.Write([]byte(`{"scores":{"1":2.5,"2":0,"3":0.5,"4":0}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestDegreeCentrality(t *testing.T) {
+	scores, err := centralityServer(t).DegreeCentrality()
+	if err != nil {
+		t.Fatalf("DegreeCentrality failed: %v", err)
+	}
+	want := map[uint64]float32{1: 1, 2: 1.0 / 3, 3: 2.0 / 3, 4: 2.0 / 3}
+	for id, w := range want {
+		if scores[id] != w {
+			t.Errorf("node %d: expected %v, got %v", id, w, scores[id])
+		}
+	}
+}
+
+func TestBetweennessCentrality(t *testing.T) {
+	scores, err := centralityServer(t).BetweennessCentrality()
+	if err != nil {
+		t.Fatalf("BetweennessCentrality failed: %v", err)
+	}
+	if len(scores) != 4 || scores[1] != 1 || scores[3] != 0.2 || scores[2] != 0 {
+		t.Errorf("unexpected scores %v", scores)
+	}
+	for id, s := range scores {
+		if s < 0 || s > 1 {
+			t.Errorf("node %d: score %v outside [0,1]", id, s)
+		}
+	}
+}
+
+func TestGraphMetrics(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/metrics" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"node_count":5,"edge_count":10,"largest_component_size":4}`))
+	})
+
+	metrics, err := client.GraphMetrics()
+	if err != nil {
+		t.Fatalf("GraphMetrics failed: %v", err)
+	}
+	if metrics.NodeCount != 5 || metrics.EdgeCount != 10 || metrics.LargestComponentSize != 4 {
+		t.Errorf("Unexpected counts: %+v", metrics)
+	}
+	if metrics.Density != 0.5 {
+		t.Errorf("Expected density 0.5, got %v", metrics.Density)
+	}
+	if metrics.AverageDegree != 4 {
+		t.Errorf("Expected average degree 4, got %v", metrics.AverageDegree)
+	}
+}
+
+func TestGraphMetricsEmptyGraph(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"node_count":0,"edge_count":0,"largest_component_size":0}`))
+	})
+
+	metrics, err := client.GraphMetrics()
+	if err != nil {
+		t.Fatalf("GraphMetrics failed: %v", err)
+	}
+	if metrics.Density != 0 || metrics.AverageDegree != 0 {
+		t.Errorf("Expected zero density and degree, got %+v", metrics)
+	}
+}
+
+func TestDetectCommunities(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/algorithms/communities" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Resolution float32 `json:"resolution"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Resolution != 1.5 {
+			t.Errorf("Expected resolution 1.5, got %v", body.Resolution)
+		}
+		// Two triangles, 1-2-3 and 4-5-6, joined by a single edge.
+		w.Write([]byte(`{"communities":{"1":0,"2":0,"3":0,"4":1,"5":1,"6":1}}`))
+	})
+
+	communities, err := client.DetectCommunities(1.5)
+	if err != nil {
+		t.Fatalf("DetectCommunities failed: %v", err)
+	}
+	if len(communities) != 6 {
+		t.Fatalf("Expected 6 assignments, got %v", communities)
+	}
+	if communities[1] != communities[3] || communities[4] != communities[6] || communities[1] == communities[4] {
+		t.Errorf("Expected two clusters {1,2,3} and {4,5,6}, got %v", communities)
+	}
+}
+
+func TestDetectCommunitiesInvalidResolution(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	for _, resolution := range []float32{0, -1} {
+		if _, err := client.DetectCommunities(resolution); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("resolution %v: expected ErrInvalidArgument, got %v", resolution, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package barqgraphdb
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// embeddingFields are the JSON fields holding embeddings.
+var embeddingFields = []string{"embedding", "query_embedding"}
+
+// embeddingCodec is a compact wire encoding for embeddings. A field such as
+// "embedding" is sent as "embedding"+suffix holding encode's output, and
+// responses carrying the suffixed field are decoded back into a number array.
+type embeddingCodec struct {
+	suffix string
+	encode func([]float32) string
+	decode func(string) ([]float32, error)
+}
+
+var base64Codec = &embeddingCodec{
+	suffix: "_b64",
+	encode: EncodeEmbeddingBase64,
+	decode: DecodeEmbeddingBase64,
+}
+
+// embeddingCodec returns the wire encoding configured for embeddings, or nil
+// to send them as JSON number arrays.
+func (c *Client) embeddingCodec() *embeddingCodec {
+	if c.embeddingPrecision == 16 {
+		return float16Codec
+	}
+	if c.base64Embeddings {
+		return base64Codec
+	}
+	return nil
+}
+
+// EncodeEmbeddingBase64 encodes an embedding as base64 of its little-endian
+// float32 bytes.
+func EncodeEmbeddingBase64(embedding []float32) string {
+	buf := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeEmbeddingBase64 decodes an embedding encoded by
+// EncodeEmbeddingBase64.
+func DecodeEmbeddingBase64(s string) ([]float32, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 embedding: %w", err)
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid base64 embedding: %d bytes is not a whole number of float32s", len(buf))
+	}
+	embedding := make([]float32, len(buf)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return embedding, nil
+}
+
+// encodeEmbeddings replaces embedding arrays in a JSON request body with
+// their encoded form. Data that is not valid JSON is returned unchanged.
+func encodeEmbeddings(data []byte, codec *embeddingCodec) []byte {
+	return transformJSON(data, codec.encodeValue)
+}
+
+// decodeEmbeddings replaces encoded embeddings in a JSON response body with
+// number arrays. Data that is not valid JSON is returned unchanged.
+func decodeEmbeddings(data []byte, codec *embeddingCodec) []byte {
+	return transformJSON(data, codec.decodeValue)
+}
+
+// transformJSON decodes data, applies transform and re-encodes the result.
+// Numbers are preserved exactly so large IDs survive the round trip. Data
+// that is not valid JSON is returned unchanged.
+func transformJSON(data []byte, transform func(interface{}) interface{}) []byte {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return data
+	}
+	out, err := json.Marshal(transform(v))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func (codec *embeddingCodec) encodeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = codec.encodeValue(val)
+		}
+		for _, key := range embeddingFields {
+			if embedding, ok := numberArray(v[key]); ok {
+				delete(out, key)
+				out[key+codec.suffix] = codec.encode(embedding)
+			}
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = codec.encodeValue(v[i])
+		}
+		return v
+	}
+	return v
+}
+
+func (codec *embeddingCodec) decodeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = codec.decodeValue(val)
+		}
+		for _, key := range embeddingFields {
+			s, ok := v[key+codec.suffix].(string)
+			if !ok {
+				continue
+			}
+			embedding, err := codec.decode(s)
+			if err != nil {
+				continue
+			}
+			numbers := make([]interface{}, len(embedding))
+			for i, f := range embedding {
+				numbers[i] = json.Number(strconv.FormatFloat(float64(f), 'g', -1, 32))
+			}
+			delete(out, key+codec.suffix)
+			out[key] = numbers
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = codec.decodeValue(v[i])
+		}
+		return v
+	}
+	return v
+}
+
+// numberArray converts a decoded JSON array of numbers to float32s. It
+// reports false for anything else, including an empty array.
+func numberArray(v interface{}) ([]float32, bool) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	embedding := make([]float32, len(list))
+	for i, item := range list {
+		n, ok := item.(json.Number)
+		if !ok {
+			return nil, false
+		}
+		f, err := strconv.ParseFloat(string(n), 32)
+		if err != nil {
+			return nil, false
+		}
+		embedding[i] = float32(f)
+	}
+	return embedding, true
+}
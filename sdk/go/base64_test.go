@@ -0,0 +1,74 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestEmbeddingBase64RoundTrip(t *testing.T) {
+	embedding := []float32{0, 1, -1, 0.1, 3.4028235e38, 1e-45, float32(math.Pi)}
+
+	got, err := DecodeEmbeddingBase64(EncodeEmbeddingBase64(embedding))
+	if err != nil {
+		t.Fatalf("DecodeEmbeddingBase64 failed: %v", err)
+	}
+	if len(got) != len(embedding) {
+		t.Fatalf("Expected %d values, got %d", len(embedding), len(got))
+	}
+	for i := range embedding {
+		if math.Float32bits(got[i]) != math.Float32bits(embedding[i]) {
+			t.Errorf("Value %d: expected %v, got %v", i, embedding[i], got[i])
+		}
+	}
+}
+
+func TestDecodeEmbeddingBase64Invalid(t *testing.T) {
+	if _, err := DecodeEmbeddingBase64("not base64!"); err == nil {
+		t.Error("Expected an error for invalid base64")
+	}
+	if _, err := DecodeEmbeddingBase64("AAA="); err == nil {
+		t.Error("Expected an error for a partial float32")
+	}
+}
+
+func TestWithBase64Embeddings(t *testing.T) {
+	embedding := []float32{0.1, -2.5, 1e-7}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/embeddings":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["embedding"]; ok {
+				t.Error("Expected no number array embedding")
+			}
+			s, _ := body["embedding_b64"].(string)
+			got, err := DecodeEmbeddingBase64(s)
+			if err != nil || len(got) != 3 || got[1] != -2.5 {
+				t.Errorf("Expected base64 embedding, got %q (%v)", s, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/embeddings/1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"id":            1,
+				"embedding_b64": EncodeEmbeddingBase64(embedding),
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithBase64Embeddings())
+
+	if err := client.SetEmbedding(1, embedding); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	got, err := client.GetEmbedding(1)
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	for i := range embedding {
+		if got[i] != embedding[i] {
+			t.Errorf("Value %d: expected %v, got %v", i, embedding[i], got[i])
+		}
+	}
+}
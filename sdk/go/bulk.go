@@ -0,0 +1,56 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// bulkChunkSize is how many items a deadline-bound bulk operation sends per
+// request.
+const bulkChunkSize = 100
+
+// PartialError reports a bulk operation that stopped part way. The first
+// Completed items were applied. The rest were not attempted, except for the
+// chunk whose request failed, which the server may or may not have applied.
+type PartialError struct {
+	Completed int
+	Total     int
+	Err       error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("barqgraphdb: completed %d of %d items: %v", e.Completed, e.Total, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// runBulk applies send to the items [0, n). Without a deadline on ctx it
+// sends everything at once. With one it sends chunks of bulkChunkSize and
+// stops early, with a *PartialError, when the time left is less than the
+// slowest chunk so far took or a chunk fails.
+func runBulk(ctx context.Context, n int, send func(ctx context.Context, lo, hi int) error) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return send(ctx, 0, n)
+	}
+
+	var slowest time.Duration
+	for lo := 0; lo < n; lo += bulkChunkSize {
+		if err := ctx.Err(); err != nil {
+			return &PartialError{Completed: lo, Total: n, Err: err}
+		}
+		if slowest > 0 && time.Until(deadline) < slowest {
+			return &PartialError{Completed: lo, Total: n, Err: context.DeadlineExceeded}
+		}
+		hi := min(lo+bulkChunkSize, n)
+		start := time.Now()
+		if err := send(ctx, lo, hi); err != nil {
+			return &PartialError{Completed: lo, Total: n, Err: err}
+		}
+		slowest = max(slowest, time.Since(start))
+	}
+	return nil
+}
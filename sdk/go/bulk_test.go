@@ -0,0 +1,101 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowBatchServer accepts node and edge batches, taking delay per request,
+// and counts the items received.
+func slowBatchServer(t *testing.T, delay time.Duration) (*Client, func() int) {
+	var mu sync.Mutex
+	received := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || (r.URL.Path != "/nodes/batch" && r.URL.Path != "/edges/batch") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Nodes []Node `json:"nodes"`
+			Edges []Edge `json:"edges"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		time.Sleep(delay)
+		mu.Lock()
+		received += len(body.Nodes) + len(body.Edges)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	return client, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return received
+	}
+}
+
+func TestCreateNodesContextPartial(t *testing.T) {
+	client, received := slowBatchServer(t, 50*time.Millisecond)
+	nodes := make([]Node, 1000)
+	for i := range nodes {
+		nodes[i] = Node{ID: uint64(i + 1), Label: "n"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 130*time.Millisecond)
+	defer cancel()
+	err := client.CreateNodesContext(ctx, nodes)
+
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected *PartialError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline as cause, got %v", partial.Err)
+	}
+	if partial.Total != 1000 || partial.Completed == 0 || partial.Completed >= 1000 || partial.Completed%bulkChunkSize != 0 {
+		t.Errorf("Expected whole chunks completed out of 1000, got %d of %d", partial.Completed, partial.Total)
+	}
+	if got := received(); got < partial.Completed {
+		t.Errorf("Expected the server to receive at least %d nodes, got %d", partial.Completed, got)
+	}
+}
+
+func TestAddEdgesContext(t *testing.T) {
+	client, received := slowBatchServer(t, 0)
+	edges := make([]Edge, 250)
+	for i := range edges {
+		edges[i] = Edge{From: 1, To: uint64(i + 2), EdgeType: "LINKS"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.AddEdgesContext(ctx, edges); err != nil {
+		t.Fatalf("AddEdgesContext failed: %v", err)
+	}
+	if got := received(); got != 250 {
+		t.Errorf("Expected 250 edges, got %d", got)
+	}
+}
+
+func TestAddEdgesWithoutDeadline(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.AddEdges(make([]Edge, 250)); err != nil {
+		t.Fatalf("AddEdges failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 1 {
+		t.Errorf("Expected a single request without a deadline, got %d", requests)
+	}
+}
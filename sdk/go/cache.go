@@ -0,0 +1,95 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// responseCache holds raw GET response bodies keyed by endpoint.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+func (rc *responseCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !rc.now().Before(entry.expires) {
+		delete(rc.entries, key)
+		return nil, false
+	}
+	return entry.body, true
+}
+
+func (rc *responseCache) set(key string, body []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries[key] = cacheEntry{body: body, expires: rc.now().Add(rc.ttl)}
+}
+
+func (rc *responseCache) delete(keys ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for _, key := range keys {
+		delete(rc.entries, key)
+	}
+}
+
+// clear drops every entry, for changes too broad to invalidate by key.
+func (rc *responseCache) clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	clear(rc.entries)
+}
+
+// doCachedGet performs a GET whose response may be served from, and is
+// stored in, the response cache when one is configured.
+func (c *Client) doCachedGet(ctx context.Context, endpoint string, result interface{}) error {
+	if c.cache == nil {
+		return c.doRequestContext(ctx, "GET", endpoint, nil, result)
+	}
+
+	body, ok := c.cache.get(endpoint)
+	if !ok {
+		var raw json.RawMessage
+		if err := c.doRequestContext(ctx, "GET", endpoint, nil, &raw); err != nil {
+			return err
+		}
+		body = raw
+		c.cache.set(endpoint, body)
+	}
+
+	return c.unmarshal(body, result)
+}
+
+// InvalidateNode drops any cached reads of the given node and its embedding.
+// It is a no-op when the client has no cache.
+func (c *Client) InvalidateNode(id uint64) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.delete(fmt.Sprintf("/nodes/%d", id), fmt.Sprintf("/embeddings/%d", id))
+}
@@ -0,0 +1,152 @@
+package barqgraphdb
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// cachingServer serves node 1 and counts how often it is fetched.
+func cachingServer(t *testing.T, fetches *int, opts ...Option) *Client {
+	label := "User"
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/nodes/1":
+			*fetches++
+			writeJSON(w, http.StatusOK, Node{ID: 1, Label: label})
+		case r.Method == "PUT" && r.URL.Path == "/nodes/1":
+			label = "Admin"
+		case r.Method == "POST" && r.URL.Path == "/nodes/rename-label":
+			label = "Admin"
+			writeJSON(w, http.StatusOK, map[string]int{"count": 1})
+		case r.Method == "POST" && r.URL.Path == "/nodes/reassign":
+			writeJSON(w, http.StatusOK, map[string]int{"count": 1})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, opts...)
+}
+
+func TestCacheHit(t *testing.T) {
+	fetches := 0
+	client := cachingServer(t, &fetches, WithCache(time.Minute))
+
+	for i := 0; i < 3; i++ {
+		node, err := client.GetNode(1)
+		if err != nil {
+			t.Fatalf("GetNode failed: %v", err)
+		}
+		if node.Label != "User" {
+			t.Errorf("Expected label User, got %q", node.Label)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected 1 fetch, got %d", fetches)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	fetches := 0
+	client := cachingServer(t, &fetches, WithCache(time.Minute))
+	now := time.Now()
+	client.cache.now = func() time.Time { return now }
+
+	client.GetNode(1)
+	now = now.Add(30 * time.Second)
+	client.GetNode(1)
+	if fetches != 1 {
+		t.Errorf("Expected cached read before TTL, got %d fetches", fetches)
+	}
+
+	now = now.Add(time.Minute)
+	client.GetNode(1)
+	if fetches != 2 {
+		t.Errorf("Expected refetch after TTL, got %d fetches", fetches)
+	}
+}
+
+func TestCacheInvalidatedByUpdate(t *testing.T) {
+	fetches := 0
+	client := cachingServer(t, &fetches, WithCache(time.Minute))
+
+	client.GetNode(1)
+	if err := client.UpdateNode(&Node{ID: 1, Label: "Admin"}); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	node, err := client.GetNode(1)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected refetch after update, got %d fetches", fetches)
+	}
+	if node.Label != "Admin" {
+		t.Errorf("Expected updated label Admin, got %q", node.Label)
+	}
+}
+
+func TestCacheClearedByBulkRewrites(t *testing.T) {
+	fetches := 0
+	client := cachingServer(t, &fetches, WithCache(time.Minute))
+
+	client.GetNode(1)
+	if _, err := client.RenameLabel("User", "Admin"); err != nil {
+		t.Fatalf("RenameLabel failed: %v", err)
+	}
+	node, err := client.GetNode(1)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if fetches != 2 || node.Label != "Admin" {
+		t.Errorf("Expected a refetch with label Admin after RenameLabel, got %d fetches and %q", fetches, node.Label)
+	}
+
+	if _, err := client.ReassignNodes(7, 9); err != nil {
+		t.Fatalf("ReassignNodes failed: %v", err)
+	}
+	client.GetNode(1)
+	if fetches != 3 {
+		t.Errorf("Expected a refetch after ReassignNodes, got %d fetches", fetches)
+	}
+}
+
+func TestCacheKeptByDryRunRename(t *testing.T) {
+	fetches := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/nodes/1":
+			fetches++
+			writeJSON(w, http.StatusOK, Node{ID: 1, Label: "User"})
+		case r.Method == "POST" && r.URL.Path == "/nodes/rename-label":
+			if r.URL.Query().Get("dry_run") != "true" {
+				t.Errorf("Expected dry_run=true on %s", r.URL)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithCache(time.Minute), WithDryRun())
+
+	client.GetNode(1)
+	if _, err := client.RenameLabel("User", "Admin"); err != nil {
+		t.Fatalf("RenameLabel failed: %v", err)
+	}
+	client.GetNode(1)
+	if fetches != 1 {
+		t.Errorf("Expected the cache to survive a dry-run rename, got %d fetches", fetches)
+	}
+	if report := client.DryRunReport(); report.Requests != 1 {
+		t.Errorf("Expected the rename in the dry-run report, got %+v", report)
+	}
+}
+
+func TestNoCacheByDefault(t *testing.T) {
+	fetches := 0
+	client := cachingServer(t, &fetches)
+
+	client.GetNode(1)
+	client.GetNode(1)
+	if fetches != 2 {
+		t.Errorf("Expected every read to hit the server, got %d fetches", fetches)
+	}
+}
@@ -3,40 +3,127 @@ package barqgraphdb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Client is the main client for interacting with Barq-GraphDB.
+//
+// A Client is safe for concurrent use by multiple goroutines and should be
+// reused rather than created per request. Options are applied once by the
+// constructor; state that changes afterwards, such as the response cache,
+// dry-run report, server capabilities and clock offset, is guarded by its
+// own lock.
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+
+	timeout          time.Duration
+	endpointTimeouts map[string]time.Duration
+
+	dryRun       bool
+	dryRunMu     sync.Mutex
+	dryRunReport DryRunReport
+
+	capsMu    sync.Mutex
+	caps      *ServerInfo
+	capsKnown bool
+
+	hedgeDelay time.Duration
+
+	pool *endpointPool
+
+	cache *responseCache
+
+	debugMu     sync.Mutex
+	debugWriter io.Writer
+
+	uniqueLabels bool
+
+	defaultEmbedding []float32
+
+	maxRetries      int
+	retryBackoff    time.Duration
+	idempotencyKeys bool
+
+	embeddingChunkSize int
+
+	fieldMap        map[string]string
+	reverseFieldMap map[string]string
+
+	requestIDGenerator func() string
+
+	streamConnectTimeout time.Duration
+
+	base64Embeddings   bool
+	embeddingPrecision int
+
+	onDuplicate DuplicatePolicy
+
+	embeddingFit    EmbeddingFit
+	embeddingFitDim int
+
+	serverTime  bool
+	clockMu     sync.Mutex
+	clockOffset time.Duration
+	clockKnown  bool
+
+	decisionNotesTemplate func(*Decision) string
+
+	autoReconnect    bool
+	reconnectBackoff time.Duration
+
+	tenantID string
+
+	maxHopsCeiling int
+	hopsPolicy     HopsPolicy
+
+	distanceMetric string
+
+	strictDecoding bool
+
+	requestSigner func(req *http.Request, body []byte) error
+
+	autoTouch bool
+
+	externalKeyField string
+
+	labelPrefix string
+
+	resultDecoders map[string]func([]HybridResult) ([]HybridResult, error)
 }
 
 // NewClient creates a new Barq-GraphDB client.
-func NewClient(baseURL string) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-	}
+func NewClient(baseURL string, opts ...Option) *Client {
+	return NewClientWithTimeout(baseURL, 30*time.Second, opts...)
 }
 
 // NewClientWithTimeout creates a new client with custom timeout.
-func NewClientWithTimeout(baseURL string, timeout time.Duration) *Client {
-	return &Client{
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+func NewClientWithTimeout(baseURL string, timeout time.Duration, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    baseURL,
+		httpClient: &http.Client{},
+		timeout:    timeout,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-// Node represents a graph node.
+// Node represents a graph node. CreatedAt and UpdatedAt are maintained by
+// the server as Unix timestamps and are nil if the server does not report
+// them.
 type Node struct {
 	ID           uint64    `json:"id"`
 	Label        string    `json:"label"`
@@ -45,19 +132,45 @@ type Node struct {
 	RuleTags     []string  `json:"rule_tags,omitempty"`
 	Timestamp    *uint64   `json:"timestamp,omitempty"`
 	HasEmbedding bool      `json:"has_embedding,omitempty"`
+	CreatedAt    *uint64   `json:"created_at,omitempty"`
+	UpdatedAt    *uint64   `json:"updated_at,omitempty"`
+	Archived     bool      `json:"archived,omitempty"`
 }
 
-// Edge represents a directed edge between nodes.
+// Edge represents a directed edge between nodes. Weight is optional; the
+// server treats an unweighted edge as having weight 1.
 type Edge struct {
-	From     uint64 `json:"from"`
-	To       uint64 `json:"to"`
-	EdgeType string `json:"edge_type"`
+	From     uint64  `json:"from"`
+	To       uint64  `json:"to"`
+	EdgeType string  `json:"edge_type"`
+	Weight   float32 `json:"weight,omitempty"`
 }
 
 // HybridParams contains parameters for hybrid queries.
 type HybridParams struct {
 	Alpha float32 `json:"alpha"`
 	Beta  float32 `json:"beta"`
+
+	// Restrict limits traversal and scoring to a subset of nodes. The zero
+	// value places no restriction.
+	Restrict Restriction `json:"restrict"`
+
+	// Exact asks for an exhaustive vector search instead of the default
+	// approximate index lookup, trading latency for recall.
+	Exact bool `json:"-"`
+}
+
+// Restriction selects the candidate nodes of a hybrid query. A node is a
+// candidate if its ID is in NodeIDs (when set) and it carries all of Tags
+// (when set).
+type Restriction struct {
+	NodeIDs []uint64 `json:"node_ids,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// empty reports whether r places no restriction.
+func (r Restriction) empty() bool {
+	return len(r.NodeIDs) == 0 && len(r.Tags) == 0
 }
 
 // DefaultHybridParams returns default hybrid parameters.
@@ -93,6 +206,24 @@ type Stats struct {
 	DecisionCount int `json:"decision_count"`
 }
 
+// DryRunReport describes what mutating requests would have changed had the
+// client not been in dry-run mode.
+type DryRunReport struct {
+	Requests          int      `json:"-"`
+	NodesCreated      int      `json:"nodes_created"`
+	EdgesCreated      int      `json:"edges_created"`
+	EmbeddingsUpdated int      `json:"embeddings_updated"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+func (r *DryRunReport) merge(other DryRunReport) {
+	r.Requests++
+	r.NodesCreated += other.NodesCreated
+	r.EdgesCreated += other.EdgesCreated
+	r.EmbeddingsUpdated += other.EmbeddingsUpdated
+	r.Warnings = append(r.Warnings, other.Warnings...)
+}
+
 // HealthResponse represents the health check response.
 type HealthResponse struct {
 	Status  string `json:"status"`
@@ -103,59 +234,351 @@ type HealthResponse struct {
 type Error struct {
 	Message    string `json:"error"`
 	StatusCode int    `json:"code"`
+
+	// Method and Endpoint identify the request that failed.
+	Method   string `json:"-"`
+	Endpoint string `json:"-"`
+
+	// RequestID is the X-Request-ID of the failed request, if the client
+	// was configured with WithRequestIDGenerator or the server assigned one.
+	RequestID string `json:"-"`
 }
 
 func (e *Error) Error() string {
-	return fmt.Sprintf("BarqError [%d]: %s", e.StatusCode, e.Message)
+	msg := fmt.Sprintf("BarqError [%d]: %s", e.StatusCode, e.Message)
+	var details []string
+	if e.Method != "" || e.Endpoint != "" {
+		details = append(details, strings.TrimSpace(e.Method+" "+e.Endpoint))
+	}
+	if e.RequestID != "" {
+		details = append(details, "request "+e.RequestID)
+	}
+	if len(details) > 0 {
+		msg += " (" + strings.Join(details, ", ") + ")"
+	}
+	return msg
+}
+
+// Is lets errors.Is match API errors against the package's sentinel errors
+// by status code.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// DimensionError is returned when the server rejects an embedding whose
+// dimension differs from the one it expects. errors.As can also extract the
+// underlying *Error.
+type DimensionError struct {
+	ExpectedDim int
+	GotDim      int
+	Err         *Error
+}
+
+func (e *DimensionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DimensionError) Unwrap() error {
+	return e.Err
+}
+
+// ConflictError is returned when a create conflicts with an existing node
+// and the server says which one, so the caller can decide whether to upsert
+// or rename. It matches ErrConflict, and errors.As can also extract the
+// underlying *Error.
+type ConflictError struct {
+	ExistingID    uint64
+	ExistingLabel string
+	Err           *Error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: conflicts with node %d (%q)", e.Err.Error(), e.ExistingID, e.ExistingLabel)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
 }
 
 func (c *Client) doRequest(method, endpoint string, body interface{}, result interface{}) error {
-	var reqBody io.Reader
+	return c.doRequestContext(context.Background(), method, endpoint, body, result)
+}
+
+// Do sends a request to endpoint, a path with an optional query string, with
+// body encoded as JSON, and decodes the JSON response into result unless it
+// is nil. The request goes through the client's usual retries, headers and
+// error handling. It serves endpoints without a method of their own and
+// calls that need ctx, for example to carry headers from
+// ContextWithHeaders.
+func (c *Client) Do(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	return c.doRequestContext(ctx, method, endpoint, body, result)
+}
+
+func (c *Client) doRequestContext(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	_, err := c.doRequestStatus(ctx, method, endpoint, body, result)
+	return err
+}
+
+// doRequestStatus is doRequestContext that also returns the HTTP status
+// code of a successful response, for endpoints whose 200 and 201 differ.
+func (c *Client) doRequestStatus(ctx context.Context, method, endpoint string, body interface{}, result interface{}) (int, error) {
+	var reqBody []byte
 	if body != nil {
-		jsonBytes, err := json.Marshal(body)
+		jsonBytes, err := c.encodeBody(body)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request: %w", err)
+			return 0, err
 		}
-		reqBody = bytes.NewReader(jsonBytes)
+		reqBody = jsonBytes
+	}
+	c.debugBody(requestDebugHeader(method, endpoint), reqBody)
+
+	header := http.Header{}
+	if c.requestIDGenerator != nil {
+		header.Set("X-Request-ID", c.requestIDGenerator())
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+endpoint, reqBody)
+	resp, err := c.sendWithRetry(ctx, method, endpoint, reqBody, header)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return 0, err
 	}
+	c.debugBody(responseDebugHeader(resp.StatusCode, method, endpoint), resp.Body)
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	if resp.StatusCode >= 400 {
+		err := parseError(resp.StatusCode, resp.Body)
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			apiErr.Method = method
+			apiErr.Endpoint = endpoint
+			apiErr.RequestID = header.Get("X-Request-ID")
+			if echoed := resp.Header.Get("X-Request-ID"); echoed != "" {
+				apiErr.RequestID = echoed
+			}
+		}
+		return resp.StatusCode, err
+	}
 
-	resp, err := c.httpClient.Do(req)
+	if result != nil {
+		if err := c.decodeBody(resp.Body, result); err != nil {
+			return resp.StatusCode, err
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// encodeBody marshals a request body, compactly encoding embeddings and
+// renaming fields for the server's schema if so configured.
+func (c *Client) encodeBody(body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if codec := c.embeddingCodec(); codec != nil {
+		data = encodeEmbeddings(data, codec)
+	}
+	if c.fieldMap != nil {
+		data = renameFields(data, c.fieldMap)
+	}
+	return data, nil
+}
+
+// decodeBody unmarshals a response body into result, first renaming fields
+// from the server's schema and decoding compact embeddings if so configured.
+func (c *Client) decodeBody(data []byte, result interface{}) error {
+	if c.fieldMap != nil {
+		data = renameFields(data, c.reverseFieldMap)
+	}
+	if codec := c.embeddingCodec(); codec != nil {
+		data = decodeEmbeddings(data, codec)
+	}
+	return c.unmarshal(data, result)
+}
+
+// unmarshal decodes a JSON response into result. With WithStrictDecoding,
+// fields that result has no place for are an error.
+func (c *Client) unmarshal(data []byte, result interface{}) error {
+	var err error
+	if c.strictDecoding {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(result)
+	} else {
+		err = json.Unmarshal(data, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+// parseError builds an *Error from a failed response, using the server's
+// error message when the body carries one. Embedding dimension mismatches
+// are returned as a *DimensionError and conflicts naming the existing node
+// as a *ConflictError, each wrapping the *Error.
+func parseError(status int, body []byte) error {
+	apiErr := &Error{Message: string(body), StatusCode: status}
+	var parsed Error
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		parsed.StatusCode = status
+		apiErr = &parsed
+	}
+
+	var dims struct {
+		ExpectedDim *int `json:"expected_dim"`
+		GotDim      *int `json:"got_dim"`
+	}
+	if json.Unmarshal(body, &dims) == nil && dims.ExpectedDim != nil && dims.GotDim != nil {
+		return &DimensionError{ExpectedDim: *dims.ExpectedDim, GotDim: *dims.GotDim, Err: apiErr}
+	}
+
+	if status == http.StatusConflict {
+		var conflict struct {
+			ExistingNode *struct {
+				ID    uint64 `json:"id"`
+				Label string `json:"label"`
+			} `json:"existing_node"`
+		}
+		if json.Unmarshal(body, &conflict) == nil && conflict.ExistingNode != nil {
+			return &ConflictError{ExistingID: conflict.ExistingNode.ID, ExistingLabel: conflict.ExistingNode.Label, Err: apiErr}
+		}
+	}
+	return apiErr
+}
+
+// response is an HTTP response whose body has been read in full.
+type response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// send performs a single HTTP round trip and reads the whole response,
+// bounded by the timeout configured for the endpoint.
+func (c *Client) send(ctx context.Context, method, endpoint string, body []byte, header http.Header) (*response, error) {
+	if timeout := c.timeoutFor(endpoint); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	resp, err := c.roundTrip(ctx, method, endpoint, body, header)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode >= 400 {
-		var apiErr Error
-		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
-			apiErr.StatusCode = resp.StatusCode
-			return &apiErr
-		}
-		return &Error{Message: string(respBody), StatusCode: resp.StatusCode}
+	return &response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// timeoutFor returns the timeout for a request to endpoint: the one set for
+// its path with WithEndpointTimeouts, or else the client default.
+func (c *Client) timeoutFor(endpoint string) time.Duration {
+	path, _, _ := strings.Cut(endpoint, "?")
+	if timeout, ok := c.endpointTimeouts[path]; ok {
+		return timeout
 	}
+	return c.timeout
+}
 
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+// roundTrip sends a request to the selected endpoint and returns the
+// response with its body unread. Headers attached to ctx and any extra
+// headers are added to the request, the latter taking precedence, before it
+// is passed to the WithRequestSigner function.
+// The caller must close the body.
+func (c *Client) roundTrip(ctx context.Context, method, endpoint string, body []byte, header http.Header) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	return c.roundTripReader(ctx, method, endpoint, reqBody, body, header)
+}
+
+// roundTripReader is roundTrip with the request body read from body, for
+// bodies too large to hold in memory. raw is the body handed to the
+// WithRequestSigner function, nil when the body is streamed.
+func (c *Client) roundTripReader(ctx context.Context, method, endpoint string, body io.Reader, raw []byte, header http.Header) (*http.Response, error) {
+	baseURL := c.baseURL
+	var ep *poolMember
+	if c.pool != nil {
+		ep = c.pool.pick()
+		baseURL = ep.baseURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, values := range headersFromContext(ctx) {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.tenantID != "" {
+		req.Header.Set("X-Tenant-ID", c.tenantID)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if c.requestSigner != nil {
+		if err := c.requestSigner(req, raw); err != nil {
+			return nil, fmt.Errorf("failed to sign request: %w", err)
 		}
 	}
 
+	resp, err := c.httpClient.Do(req)
+	if ep != nil {
+		c.pool.report(ep, err == nil && resp.StatusCode < 500)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	decodeContentEncoding(resp)
+	return resp, nil
+}
+
+// doMutation issues a request that changes server state. In dry-run mode the
+// request is flagged with dry_run=true and the server's report is recorded
+// in place of the normal result.
+func (c *Client) doMutation(method, endpoint string, body interface{}, result interface{}) error {
+	return c.doMutationContext(context.Background(), method, endpoint, body, result)
+}
+
+func (c *Client) doMutationContext(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	if !c.dryRun {
+		return c.doRequestContext(ctx, method, endpoint, body, result)
+	}
+	var report DryRunReport
+	if err := c.doRequestContext(ctx, method, withQueryParam(endpoint, "dry_run", "true"), body, &report); err != nil {
+		return err
+	}
+	c.dryRunMu.Lock()
+	c.dryRunReport.merge(report)
+	c.dryRunMu.Unlock()
 	return nil
 }
 
+// withQueryParam appends a query parameter to an endpoint that may already
+// carry a query string.
+func withQueryParam(endpoint, key, value string) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+	return endpoint + sep + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+}
+
 // Health checks the server health.
 func (c *Client) Health() (*HealthResponse, error) {
 	var result HealthResponse
@@ -165,14 +588,271 @@ func (c *Client) Health() (*HealthResponse, error) {
 
 // Stats returns database statistics.
 func (c *Client) Stats() (*Stats, error) {
+	return c.stats(context.Background())
+}
+
+func (c *Client) stats(ctx context.Context) (*Stats, error) {
 	var result Stats
-	err := c.doRequest("GET", "/stats", nil, &result)
+	err := c.doRequestContext(ctx, "GET", "/stats", nil, &result)
 	return &result, err
 }
 
-// CreateNode creates a new node.
+// CreateNode creates a new node. With WithUniqueLabels it first returns
+// ErrConflict if another node already has the same label. If a node with the
+// same ID exists, the outcome follows the client's DuplicatePolicy. The
+// node's embedding is adjusted according to WithEmbeddingFit.
 func (c *Client) CreateNode(node *Node) error {
-	return c.doRequest("POST", "/nodes", node, nil)
+	return c.CreateNodeContext(context.Background(), node)
+}
+
+// CreateNodeContext is CreateNode using ctx.
+func (c *Client) CreateNodeContext(ctx context.Context, node *Node) error {
+	if err := c.checkUniqueLabel(ctx, node.Label); err != nil {
+		return err
+	}
+	node, err := c.prepareNode(node)
+	if err != nil {
+		return err
+	}
+	err = c.doMutationContext(ctx, "POST", "/nodes", node, nil)
+	if err == nil || !errors.Is(err, ErrConflict) {
+		return err
+	}
+	switch c.onDuplicate {
+	case DuplicateIgnore:
+		return nil
+	case DuplicateOverwrite:
+		_, err = c.upsertNode(ctx, node)
+	}
+	return err
+}
+
+// DuplicatePolicy decides what CreateNode does when the server reports that
+// a node with the same ID already exists.
+type DuplicatePolicy int
+
+const (
+	// DuplicateError returns the server's conflict error, which matches
+	// ErrConflict. This is the default.
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateIgnore leaves the existing node untouched and returns nil.
+	DuplicateIgnore
+	// DuplicateOverwrite replaces the existing node using UpsertNode.
+	DuplicateOverwrite
+)
+
+// CreateNodeAutoID creates a node with a server-assigned ID and returns
+// that ID. Any ID set on node is ignored.
+func (c *Client) CreateNodeAutoID(node *Node) (uint64, error) {
+	if err := c.checkUniqueLabel(context.Background(), node.Label); err != nil {
+		return 0, err
+	}
+	prepared, err := c.prepareNode(node)
+	if err != nil {
+		return 0, err
+	}
+	prepared.ID = 0
+	var result struct {
+		NodeID *uint64 `json:"node_id"`
+		ID     uint64  `json:"id"`
+	}
+	if err := c.doMutation("POST", "/nodes", prepared, &result); err != nil {
+		return 0, err
+	}
+	if result.NodeID != nil {
+		return *result.NodeID, nil
+	}
+	return result.ID, nil
+}
+
+// CreateNodeWithEdges creates a node together with its incident edges in a
+// single atomic request. Every edge must start or end at the new node; the
+// server rejects the whole request if an edge references a missing
+// neighbour.
+func (c *Client) CreateNodeWithEdges(node *Node, edges []Edge) error {
+	for _, e := range edges {
+		if e.From != node.ID && e.To != node.ID {
+			return fmt.Errorf("%w: edge %d->%d does not reference node %d", ErrInvalidArgument, e.From, e.To, node.ID)
+		}
+	}
+	if err := c.checkUniqueLabel(context.Background(), node.Label); err != nil {
+		return err
+	}
+	prepared, err := c.prepareNode(node)
+	if err != nil {
+		return err
+	}
+	payload := struct {
+		Node  *Node  `json:"node"`
+		Edges []Edge `json:"edges"`
+	}{
+		Node:  prepared,
+		Edges: edges,
+	}
+	return c.doMutation("POST", "/nodes?with_edges=true", payload, nil)
+}
+
+// checkUniqueLabel returns ErrConflict if WithUniqueLabels is set and a node
+// with the label already exists.
+func (c *Client) checkUniqueLabel(ctx context.Context, label string) error {
+	if !c.uniqueLabels {
+		return nil
+	}
+	ids, err := c.findNodeIDsByLabel(ctx, label)
+	if err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		return fmt.Errorf("%w: label %q is already used by node %d", ErrConflict, label, ids[0])
+	}
+	return nil
+}
+
+// CreateNodes creates several nodes in a single request.
+func (c *Client) CreateNodes(nodes []Node) error {
+	return c.CreateNodesContext(context.Background(), nodes)
+}
+
+// CreateNodesContext is CreateNodes bounded by ctx. If ctx has a deadline
+// the nodes are sent in chunks, stopping before a chunk that is unlikely to
+// finish in time; the result is then a *PartialError saying how many nodes
+// were created.
+func (c *Client) CreateNodesContext(ctx context.Context, nodes []Node) error {
+	prepared := make([]*Node, len(nodes))
+	for i := range nodes {
+		var err error
+		if prepared[i], err = c.prepareNode(&nodes[i]); err != nil {
+			return fmt.Errorf("node %d: %w", nodes[i].ID, err)
+		}
+	}
+	return runBulk(ctx, len(prepared), func(ctx context.Context, lo, hi int) error {
+		payload := struct {
+			Nodes []*Node `json:"nodes"`
+		}{
+			Nodes: prepared[lo:hi],
+		}
+		return c.doMutationContext(ctx, "POST", "/nodes/batch", payload, nil)
+	})
+}
+
+// prepareNode applies client-wide defaults, the label prefix and the
+// embedding fit to a node about to be created, returning a copy so the
+// caller's node is left untouched.
+func (c *Client) prepareNode(node *Node) (*Node, error) {
+	prepared := *node
+	if len(prepared.Embedding) == 0 && c.defaultEmbedding != nil {
+		prepared.Embedding = c.defaultEmbedding
+	}
+	prepared.Label = c.prefixed(prepared.Label)
+	embedding, err := c.fitEmbedding(prepared.Embedding)
+	if err != nil {
+		return nil, err
+	}
+	prepared.Embedding = embedding
+	return &prepared, nil
+}
+
+// prefixLabel returns node with the WithLabelPrefix prefix added to its
+// label, copying it so the caller's node is left untouched.
+func (c *Client) prefixLabel(node *Node) *Node {
+	if c.labelPrefix == "" {
+		return node
+	}
+	prefixed := *node
+	prefixed.Label = c.prefixed(prefixed.Label)
+	return &prefixed
+}
+
+// prefixed adds the WithLabelPrefix prefix to label unless it already
+// carries it, as labels read back from the server do, so a node can be read,
+// changed and written back without its prefix doubling.
+func (c *Client) prefixed(label string) string {
+	if strings.HasPrefix(label, c.labelPrefix) {
+		return label
+	}
+	return c.labelPrefix + label
+}
+
+// GetNode returns a single node by ID.
+func (c *Client) GetNode(id uint64) (*Node, error) {
+	return c.GetNodeContext(context.Background(), id)
+}
+
+// GetNodeContext is GetNode using ctx.
+func (c *Client) GetNodeContext(ctx context.Context, id uint64) (*Node, error) {
+	var result Node
+	err := c.doCachedGet(ctx, fmt.Sprintf("/nodes/%d", id), &result)
+	return &result, err
+}
+
+// GetNodeByExternalKey returns the node carrying an application-defined
+// key, so callers can look nodes up by their own identifiers. The server
+// matches key against the field chosen with WithExternalKeyField, or its
+// default key field. It returns ErrNotFound if no node has the key.
+func (c *Client) GetNodeByExternalKey(key string) (*Node, error) {
+	q := url.Values{}
+	q.Set("key", key)
+	if c.externalKeyField != "" {
+		q.Set("field", c.externalKeyField)
+	}
+	var result Node
+	if err := c.doRequest("GET", "/nodes/by-key?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NodesExist reports in a single request which of the given node IDs are
+// present. Every requested ID is a key of the returned map.
+func (c *Client) NodesExist(ids []uint64) (map[uint64]bool, error) {
+	payload := struct {
+		IDs []uint64 `json:"ids"`
+	}{
+		IDs: ids,
+	}
+	var result struct {
+		Exists map[uint64]bool `json:"exists"`
+	}
+	if err := c.doRequest("POST", "/nodes/exists", payload, &result); err != nil {
+		return nil, err
+	}
+	exists := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		exists[id] = result.Exists[id]
+	}
+	return exists, nil
+}
+
+// UpdateNode replaces an existing node with the given one. With
+// WithAutoTouch the node is sent with UpdatedAt set to the current time; the
+// caller's node is not modified.
+func (c *Client) UpdateNode(node *Node) error {
+	if c.autoTouch {
+		touched := *node
+		ts := uint64(c.now().Unix())
+		touched.UpdatedAt = &ts
+		node = &touched
+	}
+	node = c.prefixLabel(node)
+	defer c.InvalidateNode(node.ID)
+	return c.doMutation("PUT", fmt.Sprintf("/nodes/%d", node.ID), node, nil)
+}
+
+// UpsertNode creates the node, or replaces it if a node with the same ID
+// already exists. It reports whether a new node was created, as signalled by
+// the server answering 201 Created rather than 200 OK.
+func (c *Client) UpsertNode(node *Node) (bool, error) {
+	return c.upsertNode(context.Background(), c.prefixLabel(node))
+}
+
+// upsertNode sends node as is; its label must already carry any prefix.
+func (c *Client) upsertNode(ctx context.Context, node *Node) (bool, error) {
+	defer c.InvalidateNode(node.ID)
+	if c.dryRun {
+		return false, c.doMutationContext(ctx, "PUT", "/nodes", node, nil)
+	}
+	status, err := c.doRequestStatus(ctx, "PUT", "/nodes", node, nil)
+	return status == http.StatusCreated, err
 }
 
 // ListNodes returns all nodes.
@@ -185,9 +865,264 @@ func (c *Client) ListNodes() ([]Node, error) {
 	return result.Nodes, err
 }
 
+// ListOption adjusts a paged node listing.
+type ListOption func(url.Values)
+
+// IncludeArchived makes a node listing include archived nodes, which are
+// excluded by default.
+func IncludeArchived() ListOption {
+	return func(q url.Values) {
+		q.Set("include_archived", "true")
+	}
+}
+
+// ListNodesPaged returns up to limit nodes starting at offset.
+func (c *Client) ListNodesPaged(offset, limit int, opts ...ListOption) ([]Node, error) {
+	q := url.Values{}
+	q.Set("offset", strconv.Itoa(offset))
+	q.Set("limit", strconv.Itoa(limit))
+	for _, opt := range opts {
+		opt(q)
+	}
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	err := c.doRequest("GET", "/nodes?"+q.Encode(), nil, &result)
+	return result.Nodes, err
+}
+
+// nodeSortFields are the fields ListNodesSorted accepts.
+var nodeSortFields = map[string]bool{"id": true, "label": true, "timestamp": true}
+
+// ListNodesSorted returns up to limit nodes starting at offset, ordered by
+// sortBy ("id", "label" or "timestamp"), descending if desc is set. Any
+// other sortBy is rejected with ErrInvalidArgument without contacting the
+// server.
+func (c *Client) ListNodesSorted(offset, limit int, sortBy string, desc bool) ([]Node, error) {
+	if !nodeSortFields[sortBy] {
+		return nil, fmt.Errorf("%w: cannot sort nodes by %q (want id, label or timestamp)", ErrInvalidArgument, sortBy)
+	}
+	order := "asc"
+	if desc {
+		order = "desc"
+	}
+	return c.ListNodesPaged(offset, limit, func(q url.Values) {
+		q.Set("sort", sortBy)
+		q.Set("order", order)
+	})
+}
+
+// SoftDeleteNode archives a node. Archived nodes keep their data but are
+// excluded from default listings until restored with RestoreNode.
+func (c *Client) SoftDeleteNode(id uint64) error {
+	defer c.InvalidateNode(id)
+	return c.doMutation("POST", fmt.Sprintf("/nodes/%d/archive", id), nil, nil)
+}
+
+// RestoreNode un-archives a node previously archived with SoftDeleteNode.
+func (c *Client) RestoreNode(id uint64) error {
+	defer c.InvalidateNode(id)
+	return c.doMutation("POST", fmt.Sprintf("/nodes/%d/restore", id), nil, nil)
+}
+
+// AddNodeTags adds tags to a node's rule tags without rewriting the node.
+// Tags the node already has are left as they are.
+func (c *Client) AddNodeTags(id uint64, tags []string) error {
+	payload := struct {
+		Add []string `json:"add"`
+	}{Add: tags}
+	defer c.InvalidateNode(id)
+	return c.doMutation("PATCH", fmt.Sprintf("/nodes/%d/tags", id), payload, nil)
+}
+
+// RemoveNodeTags removes tags from a node's rule tags without rewriting the
+// node. Tags the node does not have are ignored.
+func (c *Client) RemoveNodeTags(id uint64, tags []string) error {
+	payload := struct {
+		Remove []string `json:"remove"`
+	}{Remove: tags}
+	defer c.InvalidateNode(id)
+	return c.doMutation("PATCH", fmt.Sprintf("/nodes/%d/tags", id), payload, nil)
+}
+
+// MergeNodes merges mergeID into keepID for entity resolution: mergeID's
+// edges are re-pointed to keepID, its rule tags are added to keepID's, and
+// mergeID is deleted. If both nodes have embeddings, the survivor's is kept.
+func (c *Client) MergeNodes(keepID, mergeID uint64) error {
+	if keepID == mergeID {
+		return fmt.Errorf("%w: cannot merge node %d into itself", ErrInvalidArgument, keepID)
+	}
+	payload := struct {
+		KeepID          uint64 `json:"keep_id"`
+		MergeID         uint64 `json:"merge_id"`
+		EmbeddingPolicy string `json:"embedding_policy"`
+	}{
+		KeepID:          keepID,
+		MergeID:         mergeID,
+		EmbeddingPolicy: "keep_survivor",
+	}
+	defer c.InvalidateNode(keepID)
+	defer c.InvalidateNode(mergeID)
+	return c.doMutation("POST", "/nodes/merge", payload, nil)
+}
+
+// FindNodeIDsByLabel returns the IDs of all nodes with the given label,
+// without fetching the full node objects.
+func (c *Client) FindNodeIDsByLabel(label string) ([]uint64, error) {
+	return c.findNodeIDsByLabel(context.Background(), label)
+}
+
+func (c *Client) findNodeIDsByLabel(ctx context.Context, label string) ([]uint64, error) {
+	endpoint := "/nodes/ids?label=" + url.QueryEscape(c.prefixed(label))
+	var result struct {
+		IDs []uint64 `json:"ids"`
+	}
+	if err := c.doRequestContext(ctx, "GET", endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	if result.IDs == nil {
+		result.IDs = []uint64{}
+	}
+	return result.IDs, nil
+}
+
+// SearchNodesByLabel returns the nodes with the given label.
+func (c *Client) SearchNodesByLabel(label string) ([]Node, error) {
+	endpoint := "/nodes/search?label=" + url.QueryEscape(c.prefixed(label))
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := c.doRequest("GET", endpoint, nil, &result); err != nil {
+		return nil, err
+	}
+	return c.stripLabelPrefix(result.Nodes), nil
+}
+
+// FindNodesByTags returns the nodes carrying all of the given rule tags.
+// With WithLabelPrefix, nodes outside the prefix's namespace are left out.
+func (c *Client) FindNodesByTags(tags []string) ([]Node, error) {
+	q := url.Values{}
+	for _, tag := range tags {
+		q.Add("tag", tag)
+	}
+	var result struct {
+		Nodes []Node `json:"nodes"`
+	}
+	if err := c.doRequest("GET", "/nodes/search?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	return c.stripLabelPrefix(result.Nodes), nil
+}
+
+// stripLabelPrefix removes the WithLabelPrefix prefix from the labels of
+// nodes, dropping nodes whose label lacks it. It never returns nil.
+func (c *Client) stripLabelPrefix(nodes []Node) []Node {
+	if c.labelPrefix == "" {
+		if nodes == nil {
+			nodes = []Node{}
+		}
+		return nodes
+	}
+	stripped := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		label, ok := strings.CutPrefix(node.Label, c.labelPrefix)
+		if !ok {
+			continue
+		}
+		node.Label = label
+		stripped = append(stripped, node)
+	}
+	return stripped
+}
+
+// ListLabels returns each distinct node label with the number of nodes
+// carrying it.
+func (c *Client) ListLabels() (map[string]int, error) {
+	var result struct {
+		Labels map[string]int `json:"labels"`
+	}
+	if err := c.doRequest("GET", "/nodes/labels", nil, &result); err != nil {
+		return nil, err
+	}
+	if c.labelPrefix == "" {
+		if result.Labels == nil {
+			result.Labels = map[string]int{}
+		}
+		return result.Labels, nil
+	}
+	labels := make(map[string]int, len(result.Labels))
+	for label, count := range result.Labels {
+		if stripped, ok := strings.CutPrefix(label, c.labelPrefix); ok {
+			labels[stripped] = count
+		}
+	}
+	return labels, nil
+}
+
+// RenameLabel changes the label of every node labelled oldLabel to
+// newLabel, returning the number of nodes changed. Unless the client is in
+// dry-run mode it empties the response cache, since any cached node may be
+// affected.
+func (c *Client) RenameLabel(oldLabel, newLabel string) (int, error) {
+	if oldLabel == "" || newLabel == "" {
+		return 0, fmt.Errorf("%w: labels must be non-empty", ErrInvalidArgument)
+	}
+	payload := struct {
+		OldLabel string `json:"old_label"`
+		NewLabel string `json:"new_label"`
+	}{
+		OldLabel: c.prefixed(oldLabel),
+		NewLabel: c.prefixed(newLabel),
+	}
+	var result struct {
+		Count int `json:"count"`
+	}
+	err := c.doMutation("POST", "/nodes/rename-label", payload, &result)
+	if err == nil && !c.dryRun && c.cache != nil {
+		c.cache.clear()
+	}
+	return result.Count, err
+}
+
+// TagCooccurrence returns, for each pair of rule tags, how many nodes carry
+// both. The matrix is symmetric: counts[a][b] == counts[b][a].
+func (c *Client) TagCooccurrence() (map[string]map[string]int, error) {
+	var result struct {
+		Cooccurrence map[string]map[string]int `json:"cooccurrence"`
+	}
+	if err := c.doRequest("GET", "/nodes/tags/cooccurrence", nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Cooccurrence == nil {
+		result.Cooccurrence = map[string]map[string]int{}
+	}
+	return result.Cooccurrence, nil
+}
+
+// ReassignNodes transfers ownership of every node owned by fromAgent to
+// toAgent, returning the number of nodes reassigned. Like RenameLabel, it
+// empties the response cache.
+func (c *Client) ReassignNodes(fromAgent, toAgent uint64) (int, error) {
+	payload := struct {
+		FromAgent uint64 `json:"from_agent"`
+		ToAgent   uint64 `json:"to_agent"`
+	}{
+		FromAgent: fromAgent,
+		ToAgent:   toAgent,
+	}
+	var result struct {
+		Count int `json:"count"`
+	}
+	err := c.doMutation("POST", "/nodes/reassign", payload, &result)
+	if err == nil && !c.dryRun && c.cache != nil {
+		c.cache.clear()
+	}
+	return result.Count, err
+}
+
 // CreateEdge creates a new edge.
 func (c *Client) CreateEdge(edge *Edge) error {
-	return c.doRequest("POST", "/edges", edge, nil)
+	return c.doMutation("POST", "/edges", edge, nil)
 }
 
 // AddEdge is a convenience method to add an edge.
@@ -195,8 +1130,113 @@ func (c *Client) AddEdge(from, to uint64, edgeType string) error {
 	return c.CreateEdge(&Edge{From: from, To: to, EdgeType: edgeType})
 }
 
-// SetEmbedding sets the embedding for a node.
+// AddEdges creates several edges in a single request.
+func (c *Client) AddEdges(edges []Edge) error {
+	return c.AddEdgesContext(context.Background(), edges)
+}
+
+// AddEdgesContext is AddEdges bounded by ctx, splitting the work under a
+// deadline the same way as CreateNodesContext.
+func (c *Client) AddEdgesContext(ctx context.Context, edges []Edge) error {
+	return runBulk(ctx, len(edges), func(ctx context.Context, lo, hi int) error {
+		payload := struct {
+			Edges []Edge `json:"edges"`
+		}{
+			Edges: edges[lo:hi],
+		}
+		return c.doMutationContext(ctx, "POST", "/edges/batch", payload, nil)
+	})
+}
+
+// AddEdgeIfNotExists adds an edge unless an identical one already exists,
+// reporting whether a new edge was created. It uses the server's upsert
+// semantics so repeated sync jobs do not produce duplicate edges.
+func (c *Client) AddEdgeIfNotExists(from, to uint64, edgeType string) (bool, error) {
+	var result struct {
+		Created bool `json:"created"`
+	}
+	err := c.doMutation("PUT", "/edges", &Edge{From: from, To: to, EdgeType: edgeType}, &result)
+	return result.Created, err
+}
+
+// AddWeightedEdge is a convenience method to add an edge with a weight. It
+// returns ErrUnsupportedFeature if the server lacks weighted edge support.
+func (c *Client) AddWeightedEdge(from, to uint64, edgeType string, weight float32) error {
+	if err := c.requireFeature(FeatureWeightedEdges); err != nil {
+		return err
+	}
+	return c.CreateEdge(&Edge{From: from, To: to, EdgeType: edgeType, Weight: weight})
+}
+
+// ListEdgeTypes returns the distinct edge types present in the graph,
+// sorted alphabetically.
+func (c *Client) ListEdgeTypes() ([]string, error) {
+	var result struct {
+		Types []string `json:"types"`
+	}
+	if err := c.doRequest("GET", "/edges/types", nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Types == nil {
+		result.Types = []string{}
+	}
+	sort.Strings(result.Types)
+	return result.Types, nil
+}
+
+// NodeEdgeTypeHistogram counts a node's edges by type, which hints at the
+// role the node plays in the graph. It returns ErrNotFound if the node does
+// not exist.
+func (c *Client) NodeEdgeTypeHistogram(id uint64) (map[string]int, error) {
+	var result struct {
+		EdgeTypes map[string]int `json:"edge_types"`
+	}
+	if err := c.doRequest("GET", fmt.Sprintf("/nodes/%d/edge-types", id), nil, &result); err != nil {
+		return nil, err
+	}
+	if result.EdgeTypes == nil {
+		result.EdgeTypes = map[string]int{}
+	}
+	return result.EdgeTypes, nil
+}
+
+// ListEdgesCursor returns up to limit edges starting at cursor. Pass an
+// empty cursor for the first page and the returned nextCursor for each
+// following page; an empty nextCursor means there are no more edges. Unlike
+// offset paging, the server resumes from the cursor without rescanning
+// earlier edges.
+func (c *Client) ListEdgesCursor(cursor string, limit int) ([]Edge, string, error) {
+	q := url.Values{}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	q.Set("limit", strconv.Itoa(limit))
+
+	var result struct {
+		Edges      []Edge `json:"edges"`
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := c.doRequest("GET", "/edges?"+q.Encode(), nil, &result); err != nil {
+		return nil, "", err
+	}
+	if result.Edges == nil {
+		result.Edges = []Edge{}
+	}
+	return result.Edges, result.NextCursor, nil
+}
+
+// SetEmbedding sets the embedding for a node, adjusted according to
+// WithEmbeddingFit.
 func (c *Client) SetEmbedding(nodeID uint64, embedding []float32) error {
+	embedding, err := c.fitEmbedding(embedding)
+	if err != nil {
+		return err
+	}
+	if c.embeddingChunkSize > 0 && len(embedding) > c.embeddingChunkSize {
+		defer c.InvalidateNode(nodeID)
+		return c.setEmbeddingChunked(nodeID, embedding)
+	}
+
 	payload := struct {
 		ID        uint64    `json:"id"`
 		Embedding []float32 `json:"embedding"`
@@ -204,21 +1244,110 @@ func (c *Client) SetEmbedding(nodeID uint64, embedding []float32) error {
 		ID:        nodeID,
 		Embedding: embedding,
 	}
-	return c.doRequest("POST", "/embeddings", payload, nil)
+	defer c.InvalidateNode(nodeID)
+	return c.doMutation("POST", "/embeddings", payload, nil)
+}
+
+// UpdateEmbedding replaces a node's embedding. With reindex the server
+// updates its vector index before responding, so an immediately following
+// query sees the new embedding; otherwise reindexing may be deferred. The
+// embedding is adjusted according to WithEmbeddingFit.
+func (c *Client) UpdateEmbedding(nodeID uint64, embedding []float32, reindex bool) error {
+	embedding, err := c.fitEmbedding(embedding)
+	if err != nil {
+		return err
+	}
+	payload := struct {
+		Embedding []float32 `json:"embedding"`
+	}{
+		Embedding: embedding,
+	}
+	endpoint := withQueryParam(fmt.Sprintf("/embeddings/%d", nodeID), "reindex", strconv.FormatBool(reindex))
+	defer c.InvalidateNode(nodeID)
+	return c.doMutation("PUT", endpoint, payload, nil)
+}
+
+// GetEmbedding returns the embedding stored for a node.
+func (c *Client) GetEmbedding(nodeID uint64) ([]float32, error) {
+	var result struct {
+		ID        uint64    `json:"id"`
+		Embedding []float32 `json:"embedding"`
+	}
+	err := c.doCachedGet(context.Background(), fmt.Sprintf("/embeddings/%d", nodeID), &result)
+	return result.Embedding, err
+}
+
+// GetEmbeddings returns the embeddings stored for several nodes in a single
+// request, keyed by node ID. Nodes without an embedding are absent from the
+// map.
+func (c *Client) GetEmbeddings(nodeIDs []uint64) (map[uint64][]float32, error) {
+	payload := struct {
+		IDs []uint64 `json:"ids"`
+	}{
+		IDs: nodeIDs,
+	}
+	var result struct {
+		Embeddings map[uint64][]float32 `json:"embeddings"`
+	}
+	if err := c.doRequest("POST", "/embeddings/batch", payload, &result); err != nil {
+		return nil, err
+	}
+	if result.Embeddings == nil {
+		result.Embeddings = map[uint64][]float32{}
+	}
+	return result.Embeddings, nil
 }
 
 // HybridQueryRequest represents a hybrid query request.
 type HybridQueryRequest struct {
-	Start          uint64    `json:"start"`
-	QueryEmbedding []float32 `json:"query_embedding"`
-	MaxHops        int       `json:"max_hops"`
-	K              int       `json:"k"`
-	Alpha          float32   `json:"alpha"`
-	Beta           float32   `json:"beta"`
+	Start          uint64       `json:"start"`
+	QueryEmbedding []float32    `json:"query_embedding,omitempty"`
+	MaxHops        int          `json:"max_hops"`
+	K              int          `json:"k"`
+	Alpha          float32      `json:"alpha"`
+	Beta           float32      `json:"beta"`
+	PageToken      string       `json:"page_token,omitempty"`
+	Restrict       *Restriction `json:"restrict,omitempty"`
+	Metric         string       `json:"metric,omitempty"`
+
+	// Exact is sent as the exact query parameter, not in the body.
+	Exact bool `json:"-"`
 }
 
-// HybridQuery performs a hybrid query combining vector similarity and graph distance.
-func (c *Client) HybridQuery(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) ([]HybridResult, error) {
+// HopsPolicy decides what happens to a query whose maxHops exceeds the
+// ceiling set with WithMaxHopsCeiling.
+type HopsPolicy int
+
+const (
+	// HopsClamp lowers maxHops to the ceiling. This is the default.
+	HopsClamp HopsPolicy = iota
+	// HopsReject fails the query with ErrInvalidArgument.
+	HopsReject
+)
+
+// limitMaxHops applies the configured maxHops ceiling.
+func (c *Client) limitMaxHops(maxHops int) (int, error) {
+	if c.maxHopsCeiling <= 0 || maxHops <= c.maxHopsCeiling {
+		return maxHops, nil
+	}
+	if c.hopsPolicy == HopsReject {
+		return 0, fmt.Errorf("%w: max hops %d exceeds the ceiling of %d", ErrInvalidArgument, maxHops, c.maxHopsCeiling)
+	}
+	return c.maxHopsCeiling, nil
+}
+
+// newHybridQueryRequest builds and validates a hybrid query. A query
+// embedding is required unless Alpha is 0, in which case vector similarity
+// carries no weight and the query runs as a pure graph traversal. maxHops
+// is subject to WithMaxHopsCeiling.
+func (c *Client) newHybridQueryRequest(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) (HybridQueryRequest, error) {
+	if len(queryEmbedding) == 0 && params.Alpha != 0 {
+		return HybridQueryRequest{}, fmt.Errorf("%w: query embedding is required when alpha is non-zero", ErrInvalidArgument)
+	}
+	maxHops, err := c.limitMaxHops(maxHops)
+	if err != nil {
+		return HybridQueryRequest{}, err
+	}
 	req := HybridQueryRequest{
 		Start:          start,
 		QueryEmbedding: queryEmbedding,
@@ -226,23 +1355,115 @@ func (c *Client) HybridQuery(start uint64, queryEmbedding []float32, maxHops, k
 		K:              k,
 		Alpha:          params.Alpha,
 		Beta:           params.Beta,
+		Metric:         c.distanceMetric,
+		Exact:          params.Exact,
+	}
+	if !params.Restrict.empty() {
+		restrict := params.Restrict
+		req.Restrict = &restrict
+	}
+	return req, nil
+}
+
+// HybridQuery performs a hybrid query combining vector similarity and graph distance.
+// With params.Alpha set to 0, queryEmbedding may be nil to run a pure graph query.
+func (c *Client) HybridQuery(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) ([]HybridResult, error) {
+	return c.HybridQueryContext(context.Background(), start, queryEmbedding, maxHops, k, params)
+}
+
+// HybridQueryContext is HybridQuery using ctx.
+func (c *Client) HybridQueryContext(ctx context.Context, start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) ([]HybridResult, error) {
+	req, err := c.newHybridQueryRequest(start, queryEmbedding, maxHops, k, params)
+	if err != nil {
+		return nil, err
 	}
 
 	var result struct {
 		Results []HybridResult `json:"results"`
 	}
-	err := c.doRequest("POST", "/query/hybrid", req, &result)
-	return result.Results, err
+	if err := c.doRequestContext(ctx, "POST", exactParam("/query/hybrid", req.Exact), req, &result); err != nil {
+		return result.Results, err
+	}
+	return c.decodeResults("/query/hybrid", result.Results)
 }
 
-// RecordDecision records an agent decision.
-func (c *Client) RecordDecision(decision *Decision) (*Decision, error) {
+// HybridQueryPage runs a hybrid query one page at a time for large result
+// sets. Pass an empty token for the first page and the returned nextToken for
+// each following page; an empty nextToken means there are no more results.
+func (c *Client) HybridQueryPage(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams, token string) ([]HybridResult, string, error) {
+	req, err := c.newHybridQueryRequest(start, queryEmbedding, maxHops, k, params)
+	if err != nil {
+		return nil, "", err
+	}
+	req.PageToken = token
+
 	var result struct {
-		Status   string   `json:"status"`
-		Decision Decision `json:"decision"`
+		Results   []HybridResult `json:"results"`
+		NextToken string         `json:"next_token"`
+	}
+	if err := c.doRequest("POST", exactParam("/query/hybrid", req.Exact), req, &result); err != nil {
+		return nil, "", err
+	}
+	results, err := c.decodeResults("/query/hybrid", result.Results)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, result.NextToken, nil
+}
+
+// RecordDecision records an agent decision. With WithServerTime a decision
+// without CreatedAt is stamped with the server-adjusted current time, and
+// with WithDecisionNotesTemplate one without Notes gets templated notes.
+// The caller's decision is not modified.
+func (c *Client) RecordDecision(decision *Decision) (*Decision, error) {
+	if c.serverTime && decision.CreatedAt == nil {
+		stamped := *decision
+		ts := uint64(c.now().Unix())
+		stamped.CreatedAt = &ts
+		decision = &stamped
+	}
+	if c.decisionNotesTemplate != nil && decision.Notes == nil {
+		annotated := *decision
+		notes := c.decisionNotesTemplate(&annotated)
+		annotated.Notes = &notes
+		decision = &annotated
+	}
+	var raw json.RawMessage
+	if err := c.doRequest("POST", "/decisions", decision, &raw); err != nil {
+		return &Decision{}, err
 	}
-	err := c.doRequest("POST", "/decisions", decision, &result)
-	return &result.Decision, err
+	return c.parseDecisionResponse(raw)
+}
+
+// parseDecisionResponse reads the decision returned by RecordDecision.
+// Servers either wrap it as {"status": ..., "decision": {...}} or return the
+// bare decision; an ID or created_at given beside the envelope is used when
+// the inner decision lacks it.
+func (c *Client) parseDecisionResponse(data []byte) (*Decision, error) {
+	var envelope struct {
+		ID        *uint64         `json:"id"`
+		CreatedAt *uint64         `json:"created_at"`
+		Decision  json.RawMessage `json:"decision"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return &Decision{}, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	inner := data
+	if len(envelope.Decision) > 0 && string(envelope.Decision) != "null" {
+		inner = envelope.Decision
+	}
+	var decision Decision
+	if err := c.unmarshal(inner, &decision); err != nil {
+		return &Decision{}, err
+	}
+	if decision.ID == nil {
+		decision.ID = envelope.ID
+	}
+	if decision.CreatedAt == nil {
+		decision.CreatedAt = envelope.CreatedAt
+	}
+	return &decision, nil
 }
 
 // ListDecisions returns all decisions for a specific agent.
@@ -255,6 +1476,60 @@ func (c *Client) ListDecisions(agentID uint64) ([]Decision, error) {
 	return result.Decisions, err
 }
 
+// ListDecisionsPaged returns up to limit decisions for an agent, starting at
+// offset.
+func (c *Client) ListDecisionsPaged(agentID uint64, offset, limit int) ([]Decision, error) {
+	endpoint := fmt.Sprintf("/decisions?agent_id=%d&offset=%d&limit=%d", agentID, offset, limit)
+	var result struct {
+		Decisions []Decision `json:"decisions"`
+	}
+	err := c.doRequest("GET", endpoint, nil, &result)
+	return result.Decisions, err
+}
+
+// RecordOutcome reports how a recorded decision turned out, feeding the
+// server's outcome analytics. It returns ErrNotFound if there is no decision
+// with the ID.
+func (c *Client) RecordOutcome(decisionID uint64, success bool, reward float32) error {
+	payload := struct {
+		Success bool    `json:"success"`
+		Reward  float32 `json:"reward"`
+	}{
+		Success: success,
+		Reward:  reward,
+	}
+	return c.doMutation("POST", fmt.Sprintf("/decisions/%d/outcome", decisionID), payload, nil)
+}
+
+// RebaseDecisions points every decision rooted at oldRoot at newRoot
+// instead, e.g. after merging oldRoot into newRoot, and returns how many
+// decisions were updated.
+func (c *Client) RebaseDecisions(oldRoot, newRoot uint64) (int, error) {
+	payload := struct {
+		OldRoot uint64 `json:"old_root"`
+		NewRoot uint64 `json:"new_root"`
+	}{
+		OldRoot: oldRoot,
+		NewRoot: newRoot,
+	}
+	var result struct {
+		Count int `json:"count"`
+	}
+	err := c.doMutation("POST", "/decisions/rebase", payload, &result)
+	return result.Count, err
+}
+
+// DryRunReport returns the combined report of every mutating request sent
+// since the client was created in dry-run mode. It is empty unless the client
+// was configured with WithDryRun.
+func (c *Client) DryRunReport() DryRunReport {
+	c.dryRunMu.Lock()
+	defer c.dryRunMu.Unlock()
+	report := c.dryRunReport
+	report.Warnings = append([]string(nil), c.dryRunReport.Warnings...)
+	return report
+}
+
 // Close closes the client (no-op for HTTP client).
 func (c *Client) Close() {
 	// No-op for HTTP client
@@ -1,10 +1,34 @@
 package barqgraphdb
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 )
 
+// newTestClient starts a mock server running handler and returns a client
+// pointed at it. The server is shut down when the test finishes.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, opts...)
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
 func TestClient(t *testing.T) {
 	client := NewClient("http://localhost:3000")
 	defer client.Close()
@@ -102,3 +126,1246 @@ func TestClient(t *testing.T) {
 
 	fmt.Println("\nAll tests passed!")
 }
+
+func TestGetNodeByExternalKey(t *testing.T) {
+	var fields []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/by-key" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		fields = append(fields, q.Get("field"))
+		if q.Get("key") != "order #17" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no node with key"})
+			return
+		}
+		writeJSON(w, http.StatusOK, Node{ID: 17, Label: "Order"})
+	}
+
+	client := newTestClient(t, handler)
+	node, err := client.GetNodeByExternalKey("order #17")
+	if err != nil {
+		t.Fatalf("GetNodeByExternalKey failed: %v", err)
+	}
+	if node.ID != 17 || node.Label != "Order" {
+		t.Errorf("Expected node 17, got %+v", node)
+	}
+	if _, err := client.GetNodeByExternalKey("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	keyed := newTestClient(t, handler, WithExternalKeyField("sku:"))
+	if _, err := keyed.GetNodeByExternalKey("order #17"); err != nil {
+		t.Fatalf("GetNodeByExternalKey failed: %v", err)
+	}
+	if len(fields) != 3 || fields[0] != "" || fields[2] != "sku:" {
+		t.Errorf("Expected field only with WithExternalKeyField, got %q", fields)
+	}
+}
+
+func TestNodesExist(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/exists" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			IDs []uint64 `json:"ids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body.IDs) != 4 {
+			t.Errorf("Expected 4 IDs, got %v", body.IDs)
+		}
+		w.Write([]byte(`{"exists":{"1":true,"2":false,"3":true}}`))
+	})
+
+	exists, err := client.NodesExist([]uint64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("NodesExist failed: %v", err)
+	}
+	want := map[uint64]bool{1: true, 2: false, 3: true, 4: false}
+	if len(exists) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, exists)
+	}
+	for id, present := range want {
+		if got, ok := exists[id]; !ok || got != present {
+			t.Errorf("Expected node %d present=%v, got %v (listed %v)", id, present, got, ok)
+		}
+	}
+}
+
+func TestFindNodeIDsByLabel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/ids" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("label"); got != "User Profile" {
+			t.Errorf("Expected label %q, got %q", "User Profile", got)
+		}
+		w.Write([]byte(`{"ids":[3,1,7]}`))
+	})
+
+	ids, err := client.FindNodeIDsByLabel("User Profile")
+	if err != nil {
+		t.Fatalf("FindNodeIDsByLabel failed: %v", err)
+	}
+	if len(ids) != 3 || ids[0] != 3 || ids[1] != 1 || ids[2] != 7 {
+		t.Errorf("Expected [3 1 7], got %v", ids)
+	}
+}
+
+func TestFindNodeIDsByLabelEmpty(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ids":null}`))
+	})
+
+	ids, err := client.FindNodeIDsByLabel("Missing")
+	if err != nil {
+		t.Fatalf("FindNodeIDsByLabel failed: %v", err)
+	}
+	if ids == nil || len(ids) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", ids)
+	}
+}
+
+func TestSearchNodesByLabel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/search" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("label"); got != "User" {
+			t.Errorf("Expected label User, got %q", got)
+		}
+		w.Write([]byte(`{"nodes":[{"id":1,"label":"User"},{"id":4,"label":"User"}]}`))
+	})
+
+	nodes, err := client.SearchNodesByLabel("User")
+	if err != nil {
+		t.Fatalf("SearchNodesByLabel failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[1].ID != 4 {
+		t.Errorf("Expected nodes 1 and 4, got %+v", nodes)
+	}
+}
+
+func TestFindNodesByTags(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/search" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query()["tag"]; len(got) != 2 || got[0] != "admin" || got[1] != "ops" {
+			t.Errorf("Expected tags [admin ops], got %v", got)
+		}
+		w.Write([]byte(`{"nodes":null}`))
+	})
+
+	nodes, err := client.FindNodesByTags([]string{"admin", "ops"})
+	if err != nil {
+		t.Fatalf("FindNodesByTags failed: %v", err)
+	}
+	if nodes == nil || len(nodes) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", nodes)
+	}
+}
+
+func TestWithLabelPrefix(t *testing.T) {
+	var created []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/nodes":
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			created = append(created, node.Label)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/nodes/search":
+			if label := r.URL.Query().Get("label"); label != "" && label != "acme/User" {
+				t.Errorf("Expected prefixed label acme/User, got %q", label)
+			}
+			w.Write([]byte(`{"nodes":[{"id":1,"label":"acme/User"},{"id":2,"label":"other/User"}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithLabelPrefix("acme/"))
+
+	node := &Node{ID: 1, Label: "User"}
+	if err := client.CreateNode(node); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if len(created) != 1 || created[0] != "acme/User" {
+		t.Errorf("Expected node created as acme/User, got %v", created)
+	}
+	if node.Label != "User" {
+		t.Errorf("Expected caller's node to be left alone, got %q", node.Label)
+	}
+
+	byLabel, err := client.SearchNodesByLabel("User")
+	if err != nil {
+		t.Fatalf("SearchNodesByLabel failed: %v", err)
+	}
+	byTags, err := client.FindNodesByTags([]string{"admin"})
+	if err != nil {
+		t.Fatalf("FindNodesByTags failed: %v", err)
+	}
+	for name, nodes := range map[string][]Node{"SearchNodesByLabel": byLabel, "FindNodesByTags": byTags} {
+		if len(nodes) != 1 || nodes[0].ID != 1 || nodes[0].Label != "User" {
+			t.Errorf("%s: expected only node 1 labelled User, got %+v", name, nodes)
+		}
+	}
+}
+
+func TestWithLabelPrefixOtherPaths(t *testing.T) {
+	var mu sync.Mutex
+	var written []string
+	record := func(labels ...string) {
+		mu.Lock()
+		written = append(written, labels...)
+		mu.Unlock()
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/nodes/1" || r.URL.Path == "/nodes":
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			record(r.Method + " " + node.Label)
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/import":
+			var doc GraphDocument
+			json.NewDecoder(r.Body).Decode(&doc)
+			for _, node := range doc.Nodes {
+				record("import " + node.Label)
+			}
+		case r.URL.Path == "/nodes/rename-label":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			record("rename " + body["old_label"] + " " + body["new_label"])
+			writeJSON(w, http.StatusOK, map[string]int{"count": 2})
+		case r.URL.Path == "/nodes/labels":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"labels": map[string]int{"acme/User": 2, "acme/Doc": 1, "other/User": 5},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithLabelPrefix("acme/"))
+
+	node := &Node{ID: 1, Label: "User"}
+	if err := client.UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	if _, err := client.UpsertNode(node); err != nil {
+		t.Fatalf("UpsertNode failed: %v", err)
+	}
+	if err := client.ImportGraph(&GraphDocument{Nodes: []Node{{ID: 2, Label: "Doc"}}}); err != nil {
+		t.Fatalf("ImportGraph failed: %v", err)
+	}
+	if err := client.ImportGraphStream(context.Background(), slices.Values([]Node{{ID: 3, Label: "Doc"}}), nil); err != nil {
+		t.Fatalf("ImportGraphStream failed: %v", err)
+	}
+	if _, err := client.RenameLabel("User", "Person"); err != nil {
+		t.Fatalf("RenameLabel failed: %v", err)
+	}
+	if node.Label != "User" {
+		t.Errorf("Expected caller's node to be left alone, got %q", node.Label)
+	}
+
+	want := []string{"PUT acme/User", "PUT acme/User", "import acme/Doc", "import acme/Doc", "rename acme/User acme/Person"}
+	if !slices.Equal(written, want) {
+		t.Errorf("Expected %v, got %v", want, written)
+	}
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatalf("ListLabels failed: %v", err)
+	}
+	if len(labels) != 2 || labels["User"] != 2 || labels["Doc"] != 1 {
+		t.Errorf("Expected only this namespace's labels, stripped, got %v", labels)
+	}
+}
+
+func TestWithLabelPrefixReadModifyWrite(t *testing.T) {
+	label := "acme/User"
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/nodes/1":
+			writeJSON(w, http.StatusOK, Node{ID: 1, Label: label})
+		case r.Method == "PUT" && (r.URL.Path == "/nodes/1" || r.URL.Path == "/nodes"):
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			label = node.Label
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithLabelPrefix("acme/"))
+
+	for _, write := range []func(*Node) error{
+		client.UpdateNode,
+		func(n *Node) error { _, err := client.UpsertNode(n); return err },
+	} {
+		node, err := client.GetNode(1)
+		if err != nil {
+			t.Fatalf("GetNode failed: %v", err)
+		}
+		node.RuleTags = []string{"admin"}
+		if err := write(node); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		if label != "acme/User" {
+			t.Errorf("Expected label to stay acme/User, got %q", label)
+		}
+	}
+}
+
+func TestReassignNodes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/reassign" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]uint64
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if body["from_agent"] != 7 || body["to_agent"] != 9 {
+			t.Errorf("unexpected body %v", body)
+		}
+		w.Write([]byte(`{"count":12}`))
+	})
+
+	count, err := client.ReassignNodes(7, 9)
+	if err != nil {
+		t.Fatalf("ReassignNodes failed: %v", err)
+	}
+	if count != 12 {
+		t.Errorf("Expected 12 nodes reassigned, got %d", count)
+	}
+}
+
+func TestReassignNodesDryRun(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/reassign" || r.URL.Query().Get("dry_run") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`{}`))
+	}, WithDryRun())
+
+	if _, err := client.ReassignNodes(7, 9); err != nil {
+		t.Fatalf("ReassignNodes failed: %v", err)
+	}
+	if report := client.DryRunReport(); report.Requests != 1 {
+		t.Errorf("Expected the reassignment in the dry-run report, got %+v", report)
+	}
+}
+
+func TestRecordOutcome(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Path != "/decisions/12/outcome" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "decision not found"})
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body) != 2 || body["success"] != true || body["reward"] != 0.75 {
+			t.Errorf("unexpected body %v", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := client.RecordOutcome(12, true, 0.75); err != nil {
+		t.Fatalf("RecordOutcome failed: %v", err)
+	}
+	if err := client.RecordOutcome(99, false, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRebaseDecisions(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/decisions/rebase" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]uint64
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode body: %v", err)
+		}
+		if len(body) != 2 || body["old_root"] != 3 || body["new_root"] != 5 {
+			t.Errorf("unexpected body %v", body)
+		}
+		w.Write([]byte(`{"count":4}`))
+	})
+
+	count, err := client.RebaseDecisions(3, 5)
+	if err != nil {
+		t.Fatalf("RebaseDecisions failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 decisions rebased, got %d", count)
+	}
+}
+
+func TestRebaseDecisionsDryRun(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/decisions/rebase" || r.URL.Query().Get("dry_run") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`{}`))
+	}, WithDryRun())
+
+	if _, err := client.RebaseDecisions(3, 5); err != nil {
+		t.Fatalf("RebaseDecisions failed: %v", err)
+	}
+	if report := client.DryRunReport(); report.Requests != 1 {
+		t.Errorf("Expected the rebase in the dry-run report, got %+v", report)
+	}
+}
+
+func TestListEdgeTypes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/edges/types" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"types":["OWNS","CITES","AUTHORED"]}`))
+	})
+
+	types, err := client.ListEdgeTypes()
+	if err != nil {
+		t.Fatalf("ListEdgeTypes failed: %v", err)
+	}
+	want := []string{"AUTHORED", "CITES", "OWNS"}
+	if fmt.Sprint(types) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, types)
+	}
+}
+
+func TestListEdgeTypesEmptyGraph(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"types":[]}`))
+	})
+
+	types, err := client.ListEdgeTypes()
+	if err != nil {
+		t.Fatalf("ListEdgeTypes failed: %v", err)
+	}
+	if types == nil || len(types) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %#v", types)
+	}
+}
+
+func TestNodeEdgeTypeHistogram(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/nodes/7/edge-types":
+			w.Write([]byte(`{"edge_types":{"OWNS":3,"CITES":1}}`))
+		case "/nodes/8/edge-types":
+			w.Write([]byte(`{"edge_types":{}}`))
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "node not found"})
+		}
+	})
+
+	hist, err := client.NodeEdgeTypeHistogram(7)
+	if err != nil {
+		t.Fatalf("NodeEdgeTypeHistogram failed: %v", err)
+	}
+	if len(hist) != 2 || hist["OWNS"] != 3 || hist["CITES"] != 1 {
+		t.Errorf("Expected map[CITES:1 OWNS:3], got %v", hist)
+	}
+
+	hist, err = client.NodeEdgeTypeHistogram(8)
+	if err != nil {
+		t.Fatalf("NodeEdgeTypeHistogram failed: %v", err)
+	}
+	if hist == nil || len(hist) != 0 {
+		t.Errorf("Expected empty non-nil map, got %#v", hist)
+	}
+
+	if _, err := client.NodeEdgeTypeHistogram(99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListEdgesCursor(t *testing.T) {
+	edges := []Edge{{From: 1, To: 2, EdgeType: "A"}, {From: 2, To: 3, EdgeType: "B"}, {From: 3, To: 1, EdgeType: "A"}}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/edges" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("limit") != "2" {
+			t.Errorf("Expected limit 2, got %q", q.Get("limit"))
+		}
+		switch q.Get("cursor") {
+		case "":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"edges": edges[:2], "next_cursor": "c2"})
+		case "c2":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"edges": edges[2:], "next_cursor": ""})
+		default:
+			t.Errorf("unexpected cursor %q", q.Get("cursor"))
+		}
+	})
+
+	var all []Edge
+	cursor, pages := "", 0
+	for {
+		page, next, err := client.ListEdgesCursor(cursor, 2)
+		if err != nil {
+			t.Fatalf("ListEdgesCursor failed: %v", err)
+		}
+		all = append(all, page...)
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if pages != 2 {
+		t.Errorf("Expected 2 pages, got %d", pages)
+	}
+	if fmt.Sprint(all) != fmt.Sprint(edges) {
+		t.Errorf("Expected %v, got %v", edges, all)
+	}
+}
+
+func TestListLabels(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/labels" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"labels":{"User":3,"Document":5}}`))
+	})
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatalf("ListLabels failed: %v", err)
+	}
+	if len(labels) != 2 || labels["User"] != 3 || labels["Document"] != 5 {
+		t.Errorf("unexpected labels %v", labels)
+	}
+}
+
+func TestListLabelsEmptyGraph(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	})
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatalf("ListLabels failed: %v", err)
+	}
+	if labels == nil || len(labels) != 0 {
+		t.Errorf("Expected empty non-nil map, got %#v", labels)
+	}
+}
+
+func TestAddEdgeIfNotExists(t *testing.T) {
+	existing := map[Edge]bool{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/edges" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var edge Edge
+		json.NewDecoder(r.Body).Decode(&edge)
+		if existing[edge] {
+			writeJSON(w, http.StatusOK, map[string]bool{"created": false})
+			return
+		}
+		existing[edge] = true
+		writeJSON(w, http.StatusCreated, map[string]bool{"created": true})
+	})
+
+	created, err := client.AddEdgeIfNotExists(1, 2, "OWNS")
+	if err != nil {
+		t.Fatalf("AddEdgeIfNotExists failed: %v", err)
+	}
+	if !created {
+		t.Error("Expected first call to create the edge")
+	}
+
+	created, err = client.AddEdgeIfNotExists(1, 2, "OWNS")
+	if err != nil {
+		t.Fatalf("AddEdgeIfNotExists failed: %v", err)
+	}
+	if created {
+		t.Error("Expected second call to find the existing edge")
+	}
+}
+
+func TestUpsertNode(t *testing.T) {
+	existing := map[uint64]bool{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/nodes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var node Node
+		json.NewDecoder(r.Body).Decode(&node)
+		if existing[node.ID] {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		existing[node.ID] = true
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	created, err := client.UpsertNode(&Node{ID: 1, Label: "User"})
+	if err != nil {
+		t.Fatalf("UpsertNode failed: %v", err)
+	}
+	if !created {
+		t.Error("Expected 201 to report a created node")
+	}
+
+	created, err = client.UpsertNode(&Node{ID: 1, Label: "Admin"})
+	if err != nil {
+		t.Fatalf("UpsertNode failed: %v", err)
+	}
+	if created {
+		t.Error("Expected 200 to report an existing node")
+	}
+}
+
+func TestGetNodeTimestamps(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nodes/1":
+			w.Write([]byte(`{"id":1,"label":"User","created_at":1700000000,"updated_at":1700000500}`))
+		case "/nodes/2":
+			w.Write([]byte(`{"id":2,"label":"Legacy"}`))
+		}
+	})
+
+	node, err := client.GetNode(1)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if node.CreatedAt == nil || *node.CreatedAt != 1700000000 {
+		t.Errorf("Expected CreatedAt 1700000000, got %v", node.CreatedAt)
+	}
+	if node.UpdatedAt == nil || *node.UpdatedAt != 1700000500 {
+		t.Errorf("Expected UpdatedAt 1700000500, got %v", node.UpdatedAt)
+	}
+
+	node, err = client.GetNode(2)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if node.CreatedAt != nil || node.UpdatedAt != nil {
+		t.Errorf("Expected nil timestamps, got %v %v", node.CreatedAt, node.UpdatedAt)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	archived := map[uint64]bool{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/nodes/2/archive":
+			archived[2] = true
+		case r.Method == "POST" && r.URL.Path == "/nodes/2/restore":
+			archived[2] = false
+		case r.Method == "GET" && r.URL.Path == "/nodes":
+			q := r.URL.Query()
+			if q.Get("offset") != "0" || q.Get("limit") != "10" {
+				t.Errorf("unexpected paging %s", r.URL.RawQuery)
+			}
+			nodes := []Node{}
+			for id := uint64(1); id <= 3; id++ {
+				if archived[id] && q.Get("include_archived") != "true" {
+					continue
+				}
+				nodes = append(nodes, Node{ID: id, Label: "Item", Archived: archived[id]})
+			}
+			writeJSON(w, http.StatusOK, map[string][]Node{"nodes": nodes})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	if err := client.SoftDeleteNode(2); err != nil {
+		t.Fatalf("SoftDeleteNode failed: %v", err)
+	}
+	nodes, err := client.ListNodesPaged(0, 10)
+	if err != nil {
+		t.Fatalf("ListNodesPaged failed: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Errorf("Expected archived node excluded, got %d nodes", len(nodes))
+	}
+
+	nodes, err = client.ListNodesPaged(0, 10, IncludeArchived())
+	if err != nil {
+		t.Fatalf("ListNodesPaged failed: %v", err)
+	}
+	if len(nodes) != 3 || !nodes[1].Archived {
+		t.Errorf("Expected archived node included and flagged, got %+v", nodes)
+	}
+
+	if err := client.RestoreNode(2); err != nil {
+		t.Fatalf("RestoreNode failed: %v", err)
+	}
+	nodes, _ = client.ListNodesPaged(0, 10)
+	if len(nodes) != 3 {
+		t.Errorf("Expected restored node listed again, got %d nodes", len(nodes))
+	}
+}
+
+func TestListNodesSorted(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("sort") != "timestamp" || q.Get("order") != "desc" || q.Get("offset") != "20" || q.Get("limit") != "10" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"nodes":[{"id":9,"label":"Newest"},{"id":4,"label":"Older"}]}`))
+	})
+
+	nodes, err := client.ListNodesSorted(20, 10, "timestamp", true)
+	if err != nil {
+		t.Fatalf("ListNodesSorted failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].ID != 9 {
+		t.Errorf("unexpected nodes %+v", nodes)
+	}
+}
+
+func TestListNodesSortedInvalidField(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request, got %s", r.URL)
+	})
+
+	_, err := client.ListNodesSorted(0, 10, "embedding", false)
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestCreateNodeWithEdges(t *testing.T) {
+	existing := map[uint64]bool{1: true, 2: true}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes" || r.URL.Query().Get("with_edges") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var body struct {
+			Node  Node   `json:"node"`
+			Edges []Edge `json:"edges"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		for _, e := range body.Edges {
+			neighbour := e.From
+			if neighbour == body.Node.ID {
+				neighbour = e.To
+			}
+			if !existing[neighbour] {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("node %d not found", neighbour)})
+				return
+			}
+		}
+		if body.Node.ID != 10 || len(body.Edges) != 2 {
+			t.Errorf("unexpected payload %+v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	err := client.CreateNodeWithEdges(&Node{ID: 10, Label: "Doc"}, []Edge{
+		{From: 1, To: 10, EdgeType: "OWNS"},
+		{From: 10, To: 2, EdgeType: "CITES"},
+	})
+	if err != nil {
+		t.Fatalf("CreateNodeWithEdges failed: %v", err)
+	}
+
+	err = client.CreateNodeWithEdges(&Node{ID: 11, Label: "Doc"}, []Edge{
+		{From: 11, To: 99, EdgeType: "CITES"},
+	})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing neighbour, got %v", err)
+	}
+}
+
+func TestCreateNodeWithEdgesForeignEdge(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request, got %s", r.URL)
+	})
+
+	err := client.CreateNodeWithEdges(&Node{ID: 10, Label: "Doc"}, []Edge{{From: 1, To: 2, EdgeType: "OWNS"}})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+// tagServer serves PATCH /nodes/1/tags against an in-memory tag set.
+func tagServer(t *testing.T, tags []string) (*Client, func() []string) {
+	var mu sync.Mutex
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/nodes/1/tags" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		var body struct {
+			Add    []string `json:"add"`
+			Remove []string `json:"remove"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		for _, tag := range body.Add {
+			if !containsString(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		kept := tags[:0]
+		for _, tag := range tags {
+			if !containsString(body.Remove, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		tags = kept
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), tags...)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAddNodeTags(t *testing.T) {
+	client, current := tagServer(t, []string{"a"})
+
+	if err := client.AddNodeTags(1, []string{"b", "c"}); err != nil {
+		t.Fatalf("AddNodeTags failed: %v", err)
+	}
+	if err := client.AddNodeTags(1, []string{"a", "b"}); err != nil {
+		t.Fatalf("AddNodeTags failed: %v", err)
+	}
+	if got := current(); strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("Expected tags [a b c], got %v", got)
+	}
+}
+
+func TestRemoveNodeTags(t *testing.T) {
+	client, current := tagServer(t, []string{"a", "b", "c"})
+
+	if err := client.RemoveNodeTags(1, []string{"b"}); err != nil {
+		t.Fatalf("RemoveNodeTags failed: %v", err)
+	}
+	if err := client.RemoveNodeTags(1, []string{"b", "x"}); err != nil {
+		t.Fatalf("RemoveNodeTags failed: %v", err)
+	}
+	if got := current(); strings.Join(got, ",") != "a,c" {
+		t.Errorf("Expected tags [a c], got %v", got)
+	}
+}
+
+func TestMergeNodes(t *testing.T) {
+	nodes := map[uint64]*Node{
+		1: {ID: 1, Label: "Acme", RuleTags: []string{"org"}, Embedding: []float32{1, 0}},
+		2: {ID: 2, Label: "ACME Inc", RuleTags: []string{"customer"}, Embedding: []float32{0, 1}},
+		3: {ID: 3, Label: "Order"},
+	}
+	edges := []Edge{{From: 3, To: 2, EdgeType: "PLACED_WITH"}, {From: 2, To: 3, EdgeType: "FULFILLS"}}
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/nodes/merge":
+			var body struct {
+				KeepID          uint64 `json:"keep_id"`
+				MergeID         uint64 `json:"merge_id"`
+				EmbeddingPolicy string `json:"embedding_policy"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.EmbeddingPolicy != "keep_survivor" {
+				t.Errorf("Expected keep_survivor policy, got %q", body.EmbeddingPolicy)
+			}
+			for i := range edges {
+				if edges[i].From == body.MergeID {
+					edges[i].From = body.KeepID
+				}
+				if edges[i].To == body.MergeID {
+					edges[i].To = body.KeepID
+				}
+			}
+			keep := nodes[body.KeepID]
+			keep.RuleTags = append(keep.RuleTags, nodes[body.MergeID].RuleTags...)
+			delete(nodes, body.MergeID)
+		case r.Method == "GET":
+			var id uint64
+			fmt.Sscanf(r.URL.Path, "/nodes/%d", &id)
+			node, ok := nodes[id]
+			if !ok {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "node not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, node)
+		}
+	})
+
+	if err := client.MergeNodes(1, 2); err != nil {
+		t.Fatalf("MergeNodes failed: %v", err)
+	}
+
+	for _, e := range edges {
+		if e.From == 2 || e.To == 2 {
+			t.Errorf("Expected edge %+v to be re-pointed away from node 2", e)
+		}
+	}
+	if _, err := client.GetNode(2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected merged node to be gone, got %v", err)
+	}
+	survivor, err := client.GetNode(1)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if len(survivor.RuleTags) != 2 || survivor.Embedding[0] != 1 {
+		t.Errorf("Expected combined tags and survivor's embedding, got %+v", survivor)
+	}
+
+	if err := client.MergeNodes(1, 1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument merging a node into itself, got %v", err)
+	}
+}
+
+func TestCreateNodeAutoID(t *testing.T) {
+	responses := []map[string]interface{}{
+		{"status": "ok", "node_id": 4242},
+		{"status": "created", "id": 4242},
+	}
+	for _, response := range responses {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			if node.ID != 0 || node.Label != "User" {
+				t.Errorf("Expected node without ID, got %+v", node)
+			}
+			writeJSON(w, http.StatusCreated, response)
+		})
+
+		node := &Node{ID: 5, Label: "User"}
+		id, err := client.CreateNodeAutoID(node)
+		if err != nil {
+			t.Fatalf("CreateNodeAutoID failed: %v", err)
+		}
+		if id != 4242 {
+			t.Errorf("Expected server-assigned ID 4242 from %v, got %d", response, id)
+		}
+		if node.ID != 5 {
+			t.Errorf("Expected caller's node to be left untouched, got ID %d", node.ID)
+		}
+	}
+}
+
+func TestUpdateEmbedding(t *testing.T) {
+	for _, reindex := range []bool{true, false} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "PUT" || r.URL.Path != "/embeddings/5" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+			if got, want := r.URL.Query().Get("reindex"), fmt.Sprint(reindex); got != want {
+				t.Errorf("Expected reindex=%s, got %q", want, got)
+			}
+			var body struct {
+				Embedding []float32 `json:"embedding"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body.Embedding) != 2 || body.Embedding[1] != 0.5 {
+				t.Errorf("Expected embedding [0.25 0.5], got %v", body.Embedding)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		if err := client.UpdateEmbedding(5, []float32{0.25, 0.5}, reindex); err != nil {
+			t.Fatalf("UpdateEmbedding(reindex=%v) failed: %v", reindex, err)
+		}
+	}
+}
+
+func TestCreateNodeOnDuplicate(t *testing.T) {
+	tests := []struct {
+		policy      DuplicatePolicy
+		wantErr     bool
+		wantUpserts int
+	}{
+		{DuplicateError, true, 0},
+		{DuplicateIgnore, false, 0},
+		{DuplicateOverwrite, false, 1},
+	}
+	for _, tt := range tests {
+		var mu sync.Mutex
+		upserts := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "POST" && r.URL.Path == "/nodes":
+				writeJSON(w, http.StatusConflict, map[string]string{"error": "node 1 already exists"})
+			case r.Method == "PUT" && r.URL.Path == "/nodes":
+				mu.Lock()
+				upserts++
+				mu.Unlock()
+				w.WriteHeader(http.StatusOK)
+			default:
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+		}, WithOnDuplicate(tt.policy))
+
+		err := client.CreateNode(&Node{ID: 1, Label: "dup"})
+		if tt.wantErr && !errors.Is(err, ErrConflict) {
+			t.Errorf("policy %d: expected ErrConflict, got %v", tt.policy, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("policy %d: expected no error, got %v", tt.policy, err)
+		}
+		mu.Lock()
+		if upserts != tt.wantUpserts {
+			t.Errorf("policy %d: expected %d upserts, got %d", tt.policy, tt.wantUpserts, upserts)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestErrorDescribesRequest(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "srv-7")
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "node not found"})
+	})
+
+	_, err := client.GetNode(42)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if apiErr.Method != "GET" || apiErr.Endpoint != "/nodes/42" || apiErr.RequestID != "srv-7" {
+		t.Errorf("Expected GET /nodes/42 with request srv-7, got %+v", apiErr)
+	}
+	want := "BarqError [404]: node not found (GET /nodes/42, request srv-7)"
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestTagCooccurrence(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes/tags/cooccurrence" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"cooccurrence":{"risk":{"fraud":3,"audit":1},"fraud":{"risk":3},"audit":{"risk":1}}}`))
+	})
+
+	counts, err := client.TagCooccurrence()
+	if err != nil {
+		t.Fatalf("TagCooccurrence failed: %v", err)
+	}
+	if counts["risk"]["fraud"] != 3 || counts["fraud"]["risk"] != 3 || counts["risk"]["audit"] != 1 {
+		t.Errorf("unexpected co-occurrence matrix %v", counts)
+	}
+	if counts["fraud"]["audit"] != 0 {
+		t.Errorf("Expected no fraud/audit co-occurrence, got %d", counts["fraud"]["audit"])
+	}
+}
+
+func TestHybridQueryPage(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req HybridQueryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.PageToken {
+		case "":
+			w.Write([]byte(`{"results":[{"id":1,"score":0.9},{"id":2,"score":0.8}],"next_token":"p2"}`))
+		case "p2":
+			w.Write([]byte(`{"results":[{"id":3,"score":0.7}]}`))
+		default:
+			t.Errorf("unexpected page token %q", req.PageToken)
+		}
+	})
+
+	var ids []uint64
+	token := ""
+	for pages := 0; ; pages++ {
+		if pages == 3 {
+			t.Fatal("Expected paging to end after two pages")
+		}
+		results, next, err := client.HybridQueryPage(1, []float32{0.1}, 2, 2, DefaultHybridParams(), token)
+		if err != nil {
+			t.Fatalf("HybridQueryPage failed: %v", err)
+		}
+		for _, r := range results {
+			ids = append(ids, r.ID)
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Errorf("Expected results [1 2 3], got %v", ids)
+	}
+}
+
+func TestRenameLabel(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/rename-label" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["old_label"] != "Customer" || body["new_label"] != "Account" {
+			t.Errorf("Expected Customer -> Account, got %v", body)
+		}
+		w.Write([]byte(`{"count":12}`))
+	})
+
+	count, err := client.RenameLabel("Customer", "Account")
+	if err != nil {
+		t.Fatalf("RenameLabel failed: %v", err)
+	}
+	if count != 12 {
+		t.Errorf("Expected 12 nodes renamed, got %d", count)
+	}
+
+	if _, err := client.RenameLabel("", "Account"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for an empty label, got %v", err)
+	}
+}
+
+func TestDimensionError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":        "embedding dimension mismatch",
+			"expected_dim": 384,
+			"got_dim":      3,
+		})
+	})
+
+	err := client.SetEmbedding(1, []float32{0.1, 0.2, 0.3})
+	var dimErr *DimensionError
+	if !errors.As(err, &dimErr) {
+		t.Fatalf("Expected *DimensionError, got %v", err)
+	}
+	if dimErr.ExpectedDim != 384 || dimErr.GotDim != 3 {
+		t.Errorf("Expected 384 vs 3, got %d vs %d", dimErr.ExpectedDim, dimErr.GotDim)
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Endpoint != "/embeddings" {
+		t.Errorf("Expected the wrapped *Error with request context, got %+v", apiErr)
+	}
+}
+
+func TestParseErrorWithoutDimensions(t *testing.T) {
+	err := parseError(http.StatusBadRequest, []byte(`{"error":"bad request"}`))
+	var dimErr *DimensionError
+	if errors.As(err, &dimErr) {
+		t.Errorf("Expected a plain *Error, got %v", dimErr)
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":         "node already exists",
+			"existing_node": map[string]interface{}{"id": 7, "label": "User"},
+		})
+	})
+
+	err := client.CreateNode(&Node{ID: 7, Label: "Account"})
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("Expected *ConflictError, got %v", err)
+	}
+	if conflictErr.ExistingID != 7 || conflictErr.ExistingLabel != "User" {
+		t.Errorf("Expected existing node 7 labelled User, got %d %q", conflictErr.ExistingID, conflictErr.ExistingLabel)
+	}
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected the error to match ErrConflict, got %v", err)
+	}
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Endpoint != "/nodes" {
+		t.Errorf("Expected the wrapped *Error with request context, got %+v", apiErr)
+	}
+	if !strings.Contains(err.Error(), `conflicts with node 7 ("User")`) {
+		t.Errorf("Expected the existing node in the message, got %q", err)
+	}
+}
+
+func TestParseErrorConflictWithoutDetails(t *testing.T) {
+	err := parseError(http.StatusConflict, []byte(`{"error":"conflict"}`))
+	var conflictErr *ConflictError
+	if errors.As(err, &conflictErr) {
+		t.Errorf("Expected a plain *Error, got %v", conflictErr)
+	}
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestHybridQueryRestrict(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []map[string]json.RawMessage
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.Write([]byte(`{"results":[]}`))
+	})
+
+	params := DefaultHybridParams()
+	if _, err := client.HybridQuery(1, []float32{0.1}, 2, 5, params); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	params.Restrict = Restriction{NodeIDs: []uint64{3, 4}, Tags: []string{"tenant-a"}}
+	if _, err := client.HybridQuery(1, []float32{0.1}, 2, 5, params); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := bodies[0]["restrict"]; ok {
+		t.Errorf("Expected no restrict field for an empty restriction, got %s", bodies[0]["restrict"])
+	}
+	if got := string(bodies[1]["restrict"]); got != `{"node_ids":[3,4],"tags":["tenant-a"]}` {
+		t.Errorf("unexpected restrict field %s", got)
+	}
+}
+
+func TestRecordDecisionResponseShapes(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"envelope", `{"status":"ok","decision":{"id":9,"agent_id":1,"root_node":2,"path":[2],"score":0.5,"created_at":1700000000}}`},
+		{"bare", `{"id":9,"agent_id":1,"root_node":2,"path":[2],"score":0.5,"created_at":1700000000}`},
+		{"envelope with outer id", `{"status":"ok","id":9,"created_at":1700000000,"decision":{"agent_id":1,"root_node":2,"path":[2],"score":0.5}}`},
+	}
+	for _, tt := range tests {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(tt.body))
+		})
+
+		d, err := client.RecordDecision(&Decision{AgentID: 1, RootNode: 2, Path: []uint64{2}, Score: 0.5})
+		if err != nil {
+			t.Fatalf("%s: RecordDecision failed: %v", tt.name, err)
+		}
+		if d.ID == nil || *d.ID != 9 {
+			t.Errorf("%s: expected ID 9, got %v", tt.name, d.ID)
+		}
+		if d.CreatedAt == nil || *d.CreatedAt != 1700000000 {
+			t.Errorf("%s: expected created_at 1700000000, got %v", tt.name, d.CreatedAt)
+		}
+		if d.AgentID != 1 || d.RootNode != 2 {
+			t.Errorf("%s: unexpected decision %+v", tt.name, d)
+		}
+	}
+}
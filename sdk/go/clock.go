@@ -0,0 +1,69 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// now returns the current time, shifted to the server's clock when the
+// client was created with WithServerTime. The offset is measured once, on
+// first use; if that fails the local clock is used and the next call tries
+// again.
+func (c *Client) now() time.Time {
+	if !c.serverTime {
+		return time.Now()
+	}
+
+	c.clockMu.Lock()
+	defer c.clockMu.Unlock()
+	if !c.clockKnown {
+		offset, err := c.measureClockOffset()
+		if err != nil {
+			return time.Now()
+		}
+		c.clockOffset, c.clockKnown = offset, true
+	}
+	return time.Now().Add(c.clockOffset)
+}
+
+// measureClockOffset estimates how far the server's clock is ahead of the
+// local one. It uses the unix_ms field from GET /time, or the response's
+// Date header (second precision) if the server has no such endpoint, and
+// assumes the server read its clock halfway through the round trip.
+func (c *Client) measureClockOffset() (time.Duration, error) {
+	sent := time.Now()
+	resp, err := c.send(context.Background(), "GET", "/time", nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+
+	var serverNow time.Time
+	var result struct {
+		UnixMs int64 `json:"unix_ms"`
+	}
+	if resp.StatusCode < 400 && json.Unmarshal(resp.Body, &result) == nil && result.UnixMs > 0 {
+		serverNow = time.UnixMilli(result.UnixMs)
+	} else {
+		serverNow, err = http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			if resp.StatusCode >= 400 {
+				return 0, parseError(resp.StatusCode, resp.Body)
+			}
+			return 0, err
+		}
+	}
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return serverNow.Sub(midpoint), nil
+}
+
+// CreateNodeNow creates node with its timestamp set to the current time,
+// taken from the server's clock with WithServerTime.
+func (c *Client) CreateNodeNow(node *Node) error {
+	stamped := *node
+	ts := uint64(c.now().Unix())
+	stamped.Timestamp = &ts
+	return c.CreateNode(&stamped)
+}
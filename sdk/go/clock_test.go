@@ -0,0 +1,107 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// skewedServer reports a clock skew ahead of the local one, either from
+// GET /time or, if useDate is set, only through the Date header. It records
+// the timestamps of created nodes and decisions.
+func skewedServer(t *testing.T, skew time.Duration, useDate bool, opts ...Option) (*Client, func() []uint64) {
+	var mu sync.Mutex
+	var stamps []uint64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		serverNow := time.Now().Add(skew)
+		switch {
+		case r.URL.Path == "/time":
+			if useDate {
+				w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+				http.NotFound(w, r)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]int64{"unix_ms": serverNow.UnixMilli()})
+		case r.Method == "POST" && r.URL.Path == "/nodes":
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			mu.Lock()
+			stamps = append(stamps, *node.Timestamp)
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "POST" && r.URL.Path == "/decisions":
+			var d Decision
+			json.NewDecoder(r.Body).Decode(&d)
+			if d.CreatedAt != nil {
+				mu.Lock()
+				stamps = append(stamps, *d.CreatedAt)
+				mu.Unlock()
+			}
+			writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "ok", "decision": d})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, opts...)
+	return client, func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]uint64(nil), stamps...)
+	}
+}
+
+// near reports whether the unix timestamp ts is within 2s of want.
+func near(ts uint64, want time.Time) bool {
+	d := int64(ts) - want.Unix()
+	return d >= -2 && d <= 2
+}
+
+func TestWithServerTime(t *testing.T) {
+	skew := time.Hour
+	client, stamps := skewedServer(t, skew, false, WithServerTime())
+
+	if err := client.CreateNodeNow(&Node{ID: 1, Label: "n"}); err != nil {
+		t.Fatalf("CreateNodeNow failed: %v", err)
+	}
+	if _, err := client.RecordDecision(&Decision{AgentID: 1, RootNode: 1}); err != nil {
+		t.Fatalf("RecordDecision failed: %v", err)
+	}
+
+	got := stamps()
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 timestamps, got %v", got)
+	}
+	want := time.Now().Add(skew)
+	for _, ts := range got {
+		if !near(ts, want) {
+			t.Errorf("Expected timestamp near server time %d, got %d", want.Unix(), ts)
+		}
+	}
+}
+
+func TestWithServerTimeDateHeader(t *testing.T) {
+	skew := -2 * time.Hour
+	client, stamps := skewedServer(t, skew, true, WithServerTime())
+
+	if err := client.CreateNodeNow(&Node{ID: 1, Label: "n"}); err != nil {
+		t.Fatalf("CreateNodeNow failed: %v", err)
+	}
+	if got := stamps(); len(got) != 1 || !near(got[0], time.Now().Add(skew)) {
+		t.Errorf("Expected timestamp near server time %d, got %v", time.Now().Add(skew).Unix(), got)
+	}
+}
+
+func TestCreateNodeNowLocalClock(t *testing.T) {
+	client, stamps := skewedServer(t, time.Hour, false)
+
+	if err := client.CreateNodeNow(&Node{ID: 1, Label: "n"}); err != nil {
+		t.Fatalf("CreateNodeNow failed: %v", err)
+	}
+	if _, err := client.RecordDecision(&Decision{AgentID: 1, RootNode: 1}); err != nil {
+		t.Fatalf("RecordDecision failed: %v", err)
+	}
+	if got := stamps(); len(got) != 1 || !near(got[0], time.Now()) {
+		t.Errorf("Expected only the node stamped with local time, got %v", got)
+	}
+}
@@ -0,0 +1,79 @@
+package barqgraphdb
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decodeContentEncoding makes a gzip or deflate encoded response body read
+// as plain bytes. Go's transport only decompresses gzip it asked for itself,
+// but reverse proxies sometimes compress responses regardless. Other
+// encodings are left untouched.
+func decodeContentEncoding(resp *http.Response) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" && encoding != "deflate" {
+		return
+	}
+	resp.Body = &decodedBody{encoded: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody decompresses a response body. The decompressor is created on
+// the first Read so that opening a stream does not wait for its first bytes.
+type decodedBody struct {
+	encoded  io.ReadCloser
+	encoding string
+	r        io.Reader
+	err      error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = b.newReader()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) newReader() (io.Reader, error) {
+	br := bufio.NewReader(b.encoded)
+	header, err := br.Peek(2)
+	if len(header) == 0 && errors.Is(err, io.EOF) {
+		return br, nil // an empty body stays empty
+	}
+
+	if b.encoding == "deflate" {
+		// "deflate" should be zlib-wrapped, but some servers send raw
+		// DEFLATE data.
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode deflate response: %w", err)
+			}
+			return zr, nil
+		}
+		return flate.NewReader(br), nil
+	}
+
+	gr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode gzip response: %w", err)
+	}
+	return gr, nil
+}
+
+func (b *decodedBody) Close() error {
+	return b.encoded.Close()
+}
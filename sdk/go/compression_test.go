@@ -0,0 +1,87 @@
+package barqgraphdb
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func compress(encoding string, data []byte) []byte {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "raw-deflate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestCompressedResponses(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate"} {
+		body := compress(encoding, []byte(`{"id":3,"label":"Compressed"}`))
+		header := encoding
+		if encoding == "raw-deflate" {
+			header = "deflate"
+		}
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", header)
+			w.Write(body)
+		})
+
+		node, err := client.GetNode(3)
+		if err != nil {
+			t.Fatalf("%s: GetNode failed: %v", encoding, err)
+		}
+		if node.Label != "Compressed" {
+			t.Errorf("%s: expected label Compressed, got %q", encoding, node.Label)
+		}
+	}
+}
+
+func TestCompressedStream(t *testing.T) {
+	body := compress("gzip", []byte("{\"id\":2,\"score\":0.9}\n{\"id\":3,\"score\":0.7}\n"))
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["streaming"]}`))
+		default:
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(body)
+		}
+	})
+
+	results, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	count := 0
+	for range results {
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("HybridQueryStream failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+}
+
+func TestCompressedEmptyBody(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "n"}); err != nil {
+		t.Errorf("Expected an empty gzip-labelled body to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package barqgraphdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// memoryServer is a small in-memory mock of the server, safe for concurrent
+// requests.
+type memoryServer struct {
+	mu         sync.Mutex
+	nodes      map[uint64]Node
+	embeddings map[uint64][]float32
+	decisions  int
+}
+
+func (s *memoryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Query().Get("dry_run") == "true" {
+		writeJSON(w, http.StatusOK, DryRunReport{NodesCreated: 1, Warnings: []string{"dry run"}})
+		return
+	}
+
+	var id uint64
+	switch {
+	case r.URL.Path == "/info":
+		writeJSON(w, http.StatusOK, ServerInfo{Version: "1.0", Features: []string{FeatureWeightedEdges}})
+	case r.URL.Path == "/time":
+		writeJSON(w, http.StatusOK, map[string]int64{"unix_ms": time.Now().UnixMilli()})
+	case r.Method == "POST" && r.URL.Path == "/nodes":
+		var node Node
+		json.NewDecoder(r.Body).Decode(&node)
+		s.nodes[node.ID] = node
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "GET" && scan(r.URL.Path, "/nodes/%d", &id):
+		node, ok := s.nodes[id]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		writeJSON(w, http.StatusOK, node)
+	case r.Method == "POST" && r.URL.Path == "/embeddings":
+		var body struct {
+			ID        uint64    `json:"id"`
+			Embedding []float32 `json:"embedding"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.embeddings[body.ID] = body.Embedding
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "GET" && scan(r.URL.Path, "/embeddings/%d", &id):
+		writeJSON(w, http.StatusOK, map[string]interface{}{"id": id, "embedding": s.embeddings[id]})
+	case r.Method == "POST" && r.URL.Path == "/edges":
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "POST" && r.URL.Path == "/query/hybrid":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": []HybridResult{{ID: 1, Score: 0.5}}})
+	case r.Method == "POST" && r.URL.Path == "/decisions":
+		s.decisions++
+		var d Decision
+		json.NewDecoder(r.Body).Decode(&d)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "ok", "decision": d})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unexpected " + r.Method + " " + r.URL.Path})
+	}
+}
+
+func scan(path, format string, id *uint64) bool {
+	n, err := fmt.Sscanf(path, format, id)
+	return err == nil && n == 1 && path == fmt.Sprintf(format, *id)
+}
+
+// lockedBuffer is an io.Writer whose contents can be read while it is
+// written to.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestClientConcurrentUse hammers a client with every stateful option
+// enabled from many goroutines. Run with -race to check for data races.
+func TestClientConcurrentUse(t *testing.T) {
+	server := &memoryServer{nodes: map[uint64]Node{}, embeddings: map[uint64][]float32{}}
+	var ids int64
+	var debug lockedBuffer
+	client := newTestClient(t, server.ServeHTTP,
+		WithCache(time.Minute),
+		WithDebugBodies(&debug),
+		WithServerTime(),
+		WithRetry(2, time.Millisecond),
+		WithIdempotencyKeys(),
+		WithHedging(5*time.Millisecond),
+		WithDefaultEmbedding([]float32{0, 0}),
+		WithRequestIDGenerator(func() string {
+			return fmt.Sprintf("req-%d", atomic.AddInt64(&ids, 1))
+		}),
+	)
+	dryRun := newTestClient(t, server.ServeHTTP, WithDryRun())
+
+	const workers, iterations = 16, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*iterations)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := uint64(w*iterations + i + 1)
+				steps := []func() error{
+					func() error { return client.CreateNodeNow(&Node{ID: id, Label: "n"}) },
+					func() error { _, err := client.GetNode(id); return err },
+					func() error { return client.SetEmbedding(id, []float32{1, 0}) },
+					func() error { _, err := client.GetEmbedding(id); return err },
+					func() error { return client.AddWeightedEdge(id, 1, "LINKS", 0.5) },
+					func() error {
+						_, err := client.HybridQuery(id, []float32{1, 0}, 2, 5, DefaultHybridParams())
+						return err
+					},
+					func() error { _, err := client.RecordDecision(&Decision{AgentID: 1, RootNode: id}); return err },
+					func() error { _, err := client.ServerInfo(); return err },
+					func() error { return dryRun.CreateNode(&Node{ID: id, Label: "n"}) },
+					func() error { dryRun.DryRunReport(); return nil },
+				}
+				for _, step := range steps {
+					if err := step(); err != nil {
+						errs <- err
+					}
+				}
+				client.InvalidateNode(id)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent call failed: %v", err)
+	}
+	if got := dryRun.DryRunReport().NodesCreated; got != workers*iterations {
+		t.Errorf("Expected %d dry-run nodes, got %d", workers*iterations, got)
+	}
+	if !strings.Contains(debug.String(), "--> POST /nodes") {
+		t.Error("Expected debug output for node creation")
+	}
+}
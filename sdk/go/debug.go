@@ -0,0 +1,37 @@
+package barqgraphdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// debugBody writes a pretty-printed JSON body to the debug writer, if one is
+// configured. Bodies that are not valid JSON are written verbatim.
+func (c *Client) debugBody(header string, body []byte) {
+	if c.debugWriter == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	buf.WriteByte('\n')
+	if len(body) > 0 {
+		if json.Indent(&buf, body, "", "  ") != nil {
+			buf.Write(body)
+		}
+		buf.WriteByte('\n')
+	}
+
+	c.debugMu.Lock()
+	defer c.debugMu.Unlock()
+	c.debugWriter.Write(buf.Bytes())
+}
+
+func requestDebugHeader(method, endpoint string) string {
+	return fmt.Sprintf("--> %s %s", method, endpoint)
+}
+
+func responseDebugHeader(status int, method, endpoint string) string {
+	return fmt.Sprintf("<-- %d %s %s", status, method, endpoint)
+}
@@ -0,0 +1,70 @@
+package barqgraphdb
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// PendingDelete is the first phase of a bulk delete: the server has counted
+// what would be deleted and issued a token, but nothing is deleted until the
+// token is passed back with ConfirmDelete. Tokens are single-use and the
+// server may expire them or reject them if the data changed meanwhile.
+type PendingDelete struct {
+	Count int    `json:"count"`
+	Token string `json:"token"`
+
+	endpoint string
+}
+
+// DeleteNodesByLabel prepares deleting every node with the given label,
+// together with their edges and embeddings. Call ConfirmDelete with the
+// result to carry it out.
+func (c *Client) DeleteNodesByLabel(label string) (*PendingDelete, error) {
+	return c.prepareDelete("/nodes?label=" + url.QueryEscape(c.prefixed(label)))
+}
+
+// DeleteEdgesByType prepares deleting every edge of the given type. Call
+// ConfirmDelete with the result to carry it out.
+func (c *Client) DeleteEdgesByType(edgeType string) (*PendingDelete, error) {
+	return c.prepareDelete("/edges?type=" + url.QueryEscape(edgeType))
+}
+
+// Clear prepares deleting the whole graph: every node, edge, embedding and
+// decision. Call ConfirmDelete with the result to carry it out.
+func (c *Client) Clear() (*PendingDelete, error) {
+	return c.prepareDelete("/graph")
+}
+
+// prepareDelete runs the first phase of a bulk delete, asking the server to
+// count what a DELETE of endpoint would remove and issue a confirmation
+// token. The request is always flagged with dry_run=true, so it cannot delete
+// anything whatever the client's own dry-run setting; only ConfirmDelete
+// sends an unqualified DELETE.
+func (c *Client) prepareDelete(endpoint string) (*PendingDelete, error) {
+	var pending PendingDelete
+	if err := c.doRequest("DELETE", withQueryParam(endpoint, "dry_run", "true"), nil, &pending); err != nil {
+		return nil, err
+	}
+	if pending.Token == "" {
+		return nil, fmt.Errorf("barqgraphdb: server issued no confirmation token for DELETE %s", endpoint)
+	}
+	pending.endpoint = endpoint
+	return &pending, nil
+}
+
+// ConfirmDelete carries out a bulk delete prepared by DeleteNodesByLabel,
+// DeleteEdgesByType or Clear, passing its token back to the server, and
+// returns how many items were deleted.
+func (c *Client) ConfirmDelete(pending *PendingDelete) (int, error) {
+	if pending == nil || pending.endpoint == "" || pending.Token == "" {
+		return 0, fmt.Errorf("%w: delete was not prepared by this client", ErrInvalidArgument)
+	}
+	var result struct {
+		Deleted int `json:"deleted"`
+	}
+	err := c.doMutation("DELETE", withQueryParam(pending.endpoint, "confirm", pending.Token), nil, &result)
+	if err == nil && c.cache != nil {
+		c.cache.clear()
+	}
+	return result.Deleted, err
+}
@@ -0,0 +1,108 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// confirmServer implements two-phase bulk deletes: a DELETE with
+// dry_run=true returns a count and token, one with the matching token
+// deletes, and an unqualified one deletes straight away.
+type confirmServer struct {
+	mu      sync.Mutex
+	deleted []string
+}
+
+func (s *confirmServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		target := r.URL.Path + "?" + q.Get("label") + q.Get("type")
+		token := "tok-" + target
+		if q.Get("dry_run") == "true" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"count": 3, "token": token})
+			return
+		}
+		switch q.Get("confirm") {
+		case "", token:
+			s.mu.Lock()
+			s.deleted = append(s.deleted, target)
+			s.mu.Unlock()
+			writeJSON(w, http.StatusOK, map[string]int{"deleted": 3})
+		default:
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "invalid confirmation token"})
+		}
+	}
+}
+
+func TestBulkDeleteConfirmFlow(t *testing.T) {
+	srv := &confirmServer{}
+	client := newTestClient(t, srv.handler(t))
+
+	prepare := map[string]func() (*PendingDelete, error){
+		"/nodes?User": func() (*PendingDelete, error) { return client.DeleteNodesByLabel("User") },
+		"/edges?OWNS": func() (*PendingDelete, error) { return client.DeleteEdgesByType("OWNS") },
+		"/graph?":     client.Clear,
+	}
+	for target, prepareFn := range prepare {
+		pending, err := prepareFn()
+		if err != nil {
+			t.Fatalf("%s: prepare failed: %v", target, err)
+		}
+		if pending.Count != 3 || pending.Token == "" {
+			t.Errorf("%s: expected a count of 3 and a token, got %+v", target, pending)
+		}
+		if len(srv.deleted) != 0 {
+			t.Fatalf("%s: expected nothing deleted before confirming, got %v", target, srv.deleted)
+		}
+
+		deleted, err := client.ConfirmDelete(pending)
+		if err != nil {
+			t.Fatalf("%s: ConfirmDelete failed: %v", target, err)
+		}
+		if deleted != 3 || len(srv.deleted) != 1 || srv.deleted[0] != target {
+			t.Errorf("%s: expected 3 deleted from %s, got %d from %v", target, target, deleted, srv.deleted)
+		}
+		srv.deleted = nil
+	}
+}
+
+func TestConfirmDeleteRejectsBadToken(t *testing.T) {
+	srv := &confirmServer{}
+	client := newTestClient(t, srv.handler(t))
+
+	pending, err := client.DeleteNodesByLabel("User")
+	if err != nil {
+		t.Fatalf("DeleteNodesByLabel failed: %v", err)
+	}
+	pending.Token = "guessed"
+	if _, err := client.ConfirmDelete(pending); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected the server to reject a wrong token, got %v", err)
+	}
+	if _, err := client.ConfirmDelete(&PendingDelete{Count: 1, Token: "tok"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for an unprepared delete, got %v", err)
+	}
+	if len(srv.deleted) != 0 {
+		t.Errorf("Expected nothing deleted, got %v", srv.deleted)
+	}
+}
+
+func TestBulkDeletePrepareWithDryRunClient(t *testing.T) {
+	srv := &confirmServer{}
+	client := newTestClient(t, srv.handler(t), WithDryRun())
+
+	pending, err := client.Clear()
+	if err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if _, err := client.ConfirmDelete(pending); err != nil {
+		t.Fatalf("ConfirmDelete failed: %v", err)
+	}
+	if len(srv.deleted) != 0 {
+		t.Errorf("Expected a dry-run client to delete nothing, got %v", srv.deleted)
+	}
+}
@@ -0,0 +1,77 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// StatsDelta is the difference between a Stats snapshot and a baseline,
+// current minus baseline, so growth is positive and shrinkage negative.
+type StatsDelta struct {
+	At            time.Time
+	Current       Stats
+	NodeCount     int
+	EdgeCount     int
+	VectorCount   int
+	DecisionCount int
+}
+
+// Changed reports whether any count differs from the baseline.
+func (d StatsDelta) Changed() bool {
+	return d.NodeCount != 0 || d.EdgeCount != 0 || d.VectorCount != 0 || d.DecisionCount != 0
+}
+
+func newStatsDelta(current, baseline Stats, at time.Time) StatsDelta {
+	return StatsDelta{
+		At:            at,
+		Current:       current,
+		NodeCount:     current.NodeCount - baseline.NodeCount,
+		EdgeCount:     current.EdgeCount - baseline.EdgeCount,
+		VectorCount:   current.VectorCount - baseline.VectorCount,
+		DecisionCount: current.DecisionCount - baseline.DecisionCount,
+	}
+}
+
+// WatchDrift polls Stats every interval and sends its difference from
+// baseline on the returned channel, for spotting unexpected growth or
+// shrinkage of the graph. The first poll happens immediately; if it fails
+// the error is returned and nothing is started. Later failed polls are
+// skipped. The channel is closed once ctx is canceled.
+func (c *Client) WatchDrift(ctx context.Context, interval time.Duration, baseline Stats) (<-chan StatsDelta, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("%w: drift interval must be positive, got %v", ErrInvalidArgument, interval)
+	}
+	first, err := c.stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan StatsDelta)
+	go func() {
+		defer close(deltas)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		current := first
+		for {
+			if current != nil {
+				select {
+				case deltas <- newStatsDelta(*current, baseline, time.Now()):
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+			current, err = c.stats(ctx)
+			if err != nil {
+				current = nil
+			}
+		}
+	}()
+	return deltas, nil
+}
@@ -0,0 +1,68 @@
+package barqgraphdb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchDrift(t *testing.T) {
+	var polls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/stats" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		n := int(atomic.AddInt32(&polls, 1))
+		if n == 2 {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "busy"})
+			return
+		}
+		writeJSON(w, http.StatusOK, Stats{NodeCount: 10 + n, EdgeCount: 20 - n, VectorCount: 10, DecisionCount: 3})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	baseline := Stats{NodeCount: 10, EdgeCount: 20, VectorCount: 10, DecisionCount: 3}
+	deltas, err := client.WatchDrift(ctx, 10*time.Millisecond, baseline)
+	if err != nil {
+		t.Fatalf("WatchDrift failed: %v", err)
+	}
+
+	first := <-deltas
+	if first.NodeCount != 1 || first.EdgeCount != -1 || first.VectorCount != 0 || !first.Changed() {
+		t.Errorf("Expected +1 nodes and -1 edges, got %+v", first)
+	}
+	second := <-deltas
+	if second.NodeCount != 3 || second.EdgeCount != -3 || second.Current.NodeCount != 13 {
+		t.Errorf("Expected the failed poll to be skipped and +3 nodes next, got %+v", second)
+	}
+
+	cancel()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-deltas:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("Expected the channel to close after cancellation")
+		}
+	}
+}
+
+func TestWatchDriftInitialError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "down"})
+	})
+
+	if _, err := client.WatchDrift(context.Background(), time.Second, Stats{}); err == nil {
+		t.Error("Expected the first poll's error to be returned")
+	}
+	if _, err := client.WatchDrift(context.Background(), 0, Stats{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for a zero interval, got %v", err)
+	}
+}
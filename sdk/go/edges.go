@@ -0,0 +1,63 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxExactFloatID is the largest ID a float64 holds exactly (2^53).
+const maxExactFloatID = 1 << 53
+
+// EdgeFromStrings builds an edge from IDs given as text, as they often arrive
+// from CSV files or JSON produced by other languages. Besides plain integers
+// it accepts whole-number decimals such as "42.0" or "4.2e1" up to 2^53.
+// Empty, non-numeric, negative, fractional and overflowing IDs are reported
+// as ErrInvalidArgument, as is an empty edge type.
+func EdgeFromStrings(from, to string, edgeType string) (Edge, error) {
+	fromID, err := parseEdgeID("from", from)
+	if err != nil {
+		return Edge{}, err
+	}
+	toID, err := parseEdgeID("to", to)
+	if err != nil {
+		return Edge{}, err
+	}
+	if edgeType == "" {
+		return Edge{}, fmt.Errorf("%w: edge type is required", ErrInvalidArgument)
+	}
+	return Edge{From: fromID, To: toID, EdgeType: edgeType}, nil
+}
+
+// parseEdgeID parses the from or to ID of an edge.
+func parseEdgeID(field, s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("%w: %s ID is empty", ErrInvalidArgument, field)
+	}
+
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err == nil {
+		return id, nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%w: %s ID %q overflows uint64", ErrInvalidArgument, field, s)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	switch {
+	case err != nil && !errors.Is(err, strconv.ErrRange), math.IsNaN(f):
+		return 0, fmt.Errorf("%w: %s ID %q is not numeric", ErrInvalidArgument, field, s)
+	case f < 0:
+		return 0, fmt.Errorf("%w: %s ID %q is negative", ErrInvalidArgument, field, s)
+	case f >= math.MaxUint64:
+		return 0, fmt.Errorf("%w: %s ID %q overflows uint64", ErrInvalidArgument, field, s)
+	case f != math.Trunc(f):
+		return 0, fmt.Errorf("%w: %s ID %q is not a whole number", ErrInvalidArgument, field, s)
+	case f > maxExactFloatID:
+		return 0, fmt.Errorf("%w: %s ID %q is too large to be exact as a decimal", ErrInvalidArgument, field, s)
+	}
+	return uint64(f), nil
+}
@@ -0,0 +1,55 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEdgeFromStrings(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     Edge
+	}{
+		{"1", "2", Edge{From: 1, To: 2, EdgeType: "LINKS"}},
+		{" 18446744073709551615 ", "0", Edge{From: 18446744073709551615, To: 0, EdgeType: "LINKS"}},
+		{"42.0", "4.2e1", Edge{From: 42, To: 42, EdgeType: "LINKS"}},
+	}
+	for _, tt := range tests {
+		got, err := EdgeFromStrings(tt.from, tt.to, "LINKS")
+		if err != nil {
+			t.Errorf("EdgeFromStrings(%q, %q) failed: %v", tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EdgeFromStrings(%q, %q): expected %+v, got %+v", tt.from, tt.to, tt.want, got)
+		}
+	}
+}
+
+func TestEdgeFromStringsInvalid(t *testing.T) {
+	tests := []struct {
+		from, to, edgeType string
+		want               string
+	}{
+		{"18446744073709551616", "1", "LINKS", "from ID \"18446744073709551616\" overflows uint64"},
+		{"1", "1e20", "LINKS", "to ID \"1e20\" overflows uint64"},
+		{"abc", "1", "LINKS", "from ID \"abc\" is not numeric"},
+		{"1", "NaN", "LINKS", "to ID \"NaN\" is not numeric"},
+		{"", "1", "LINKS", "from ID is empty"},
+		{"-3", "1", "LINKS", "from ID \"-3\" is negative"},
+		{"1", "2.5", "LINKS", "to ID \"2.5\" is not a whole number"},
+		{"1e17", "1", "LINKS", "too large to be exact"},
+		{"1", "2", "", "edge type is required"},
+	}
+	for _, tt := range tests {
+		_, err := EdgeFromStrings(tt.from, tt.to, tt.edgeType)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("EdgeFromStrings(%q, %q, %q): expected ErrInvalidArgument, got %v", tt.from, tt.to, tt.edgeType, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("EdgeFromStrings(%q, %q, %q): expected error containing %q, got %q", tt.from, tt.to, tt.edgeType, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package barqgraphdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// CosineSimilarity returns the cosine similarity of two vectors. It returns 0
+// if the vectors differ in length or either has zero magnitude.
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+// Distance metrics a server may use to compare embeddings, for
+// WithDistanceMetric.
+const (
+	MetricCosine = "cosine"
+	MetricL2     = "l2"
+	MetricDot    = "dot"
+)
+
+// CosineSimilarity is the package-level CosineSimilarity, checked against
+// the client's configured metric. If WithDistanceMetric selected a metric
+// other than cosine, the similarity is still returned along with an error
+// matching ErrMetricMismatch, warning that it will not agree with the
+// server's scores.
+func (c *Client) CosineSimilarity(a, b []float32) (float32, error) {
+	sim := CosineSimilarity(a, b)
+	if c.distanceMetric != "" && c.distanceMetric != MetricCosine {
+		return sim, fmt.Errorf("%w: local cosine similarity, server uses %s", ErrMetricMismatch, c.distanceMetric)
+	}
+	return sim, nil
+}
+
+// CompareEmbeddings asks the server for the similarity between the stored
+// embeddings of two nodes, using the server's own metric so the value is
+// consistent with server-side ranking. It returns ErrNotFound if either node
+// has no embedding.
+func (c *Client) CompareEmbeddings(idA, idB uint64) (float32, error) {
+	endpoint := fmt.Sprintf("/embeddings/compare?a=%d&b=%d", idA, idB)
+	var result struct {
+		Similarity float32 `json:"similarity"`
+	}
+	err := c.doRequest("GET", endpoint, nil, &result)
+	return result.Similarity, err
+}
+
+// Polling intervals for WaitForIndexed.
+const (
+	indexPollInitial = 50 * time.Millisecond
+	indexPollMax     = time.Second
+)
+
+// indexProbeK is how many neighbours WaitForIndexed asks for when looking
+// for a node among the results of searching with its own embedding, leaving
+// room for other nodes with identical embeddings.
+const indexProbeK = 10
+
+// WaitForIndexed waits until a node's embedding is visible to vector
+// search, which may lag behind SetEmbedding while the server indexes it
+// asynchronously. It polls by searching with the node's own embedding until
+// the node shows up in the results, and returns an error wrapping
+// context.DeadlineExceeded if that does not happen within timeout.
+func (c *Client) WaitForIndexed(nodeID uint64, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	wait := indexPollInitial
+	var embedding []float32
+	for {
+		indexed, err := c.probeIndexed(ctx, nodeID, &embedding)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if indexed {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("node %d not indexed within %v: %w", nodeID, timeout, context.DeadlineExceeded)
+		}
+		wait = min(wait*2, indexPollMax)
+	}
+}
+
+// probeIndexed reports whether nodeID is found by searching with its
+// embedding, fetching the embedding into *embedding on first success. A node
+// whose embedding is not stored yet is reported as not indexed.
+func (c *Client) probeIndexed(ctx context.Context, nodeID uint64, embedding *[]float32) (bool, error) {
+	if len(*embedding) == 0 {
+		fetched, err := c.GetEmbedding(nodeID)
+		if errors.Is(err, ErrNotFound) || (err == nil && len(fetched) == 0) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		*embedding = fetched
+	}
+	results, err := c.vectorSearch(ctx, VectorSearchRequest{QueryEmbedding: *embedding, K: indexProbeK})
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(results, func(r HybridResult) bool { return r.ID == nodeID }), nil
+}
+
+// EmbeddingNorm returns the L2 magnitude of a node's stored embedding,
+// computed client-side from GetEmbedding. Values far from 1 flag
+// unnormalized embeddings and a 0 flags a degenerate one. It returns
+// ErrNotFound if the node has no embedding.
+func (c *Client) EmbeddingNorm(nodeID uint64) (float32, error) {
+	embedding, err := c.GetEmbedding(nodeID)
+	if err != nil {
+		return 0, err
+	}
+	if len(embedding) == 0 {
+		return 0, fmt.Errorf("%w: node %d has no embedding", ErrNotFound, nodeID)
+	}
+	var sum float64
+	for _, v := range embedding {
+		sum += float64(v) * float64(v)
+	}
+	return float32(math.Sqrt(sum)), nil
+}
+
+// SimilarityMatrix fetches the embeddings of the given nodes and returns
+// their pairwise cosine similarities, where matrix[i][j] compares
+// nodeIDs[i] with nodeIDs[j]. It fails, naming the nodes, if any of them has
+// no embedding.
+func (c *Client) SimilarityMatrix(nodeIDs []uint64) ([][]float32, error) {
+	embeddings, err := c.GetEmbeddings(nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []uint64
+	vectors := make([][]float32, len(nodeIDs))
+	for i, id := range nodeIDs {
+		emb, ok := embeddings[id]
+		if !ok || len(emb) == 0 {
+			missing = append(missing, id)
+			continue
+		}
+		vectors[i] = emb
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("barqgraphdb: nodes without embeddings: %v", missing)
+	}
+
+	matrix := make([][]float32, len(vectors))
+	for i := range matrix {
+		matrix[i] = make([]float32, len(vectors))
+	}
+	for i := range vectors {
+		matrix[i][i] = 1
+		for j := i + 1; j < len(vectors); j++ {
+			sim := CosineSimilarity(vectors[i], vectors[j])
+			matrix[i][j] = sim
+			matrix[j][i] = sim
+		}
+	}
+	return matrix, nil
+}
+
+// embeddingChunk is one piece of an embedding uploaded in several requests.
+// The server reassembles the chunks in ChunkIndex order once all
+// TotalChunks have arrived.
+type embeddingChunk struct {
+	ID          uint64    `json:"id"`
+	ChunkIndex  int       `json:"chunk_index"`
+	TotalChunks int       `json:"total_chunks"`
+	Values      []float32 `json:"values"`
+}
+
+// setEmbeddingChunked uploads an embedding in pieces of at most the
+// configured chunk size, in order.
+func (c *Client) setEmbeddingChunked(nodeID uint64, embedding []float32) error {
+	size := c.embeddingChunkSize
+	total := (len(embedding) + size - 1) / size
+	for i := 0; i < total; i++ {
+		end := (i + 1) * size
+		if end > len(embedding) {
+			end = len(embedding)
+		}
+		chunk := embeddingChunk{
+			ID:          nodeID,
+			ChunkIndex:  i,
+			TotalChunks: total,
+			Values:      embedding[i*size : end],
+		}
+		if err := c.doMutation("POST", "/embeddings/chunk", chunk, nil); err != nil {
+			return fmt.Errorf("failed to upload embedding chunk %d/%d: %w", i+1, total, err)
+		}
+	}
+	return nil
+}
+
+// EmbeddingFit decides how embeddings whose length differs from the
+// index's dimension are handled before they are sent.
+type EmbeddingFit int
+
+const (
+	// FitError rejects embeddings whose length differs from the index's
+	// dimension with ErrInvalidArgument, without contacting the server.
+	// Without a dimension they are sent unchanged, leaving the server to
+	// reject a mismatched one with a *DimensionError. This is the default.
+	FitError EmbeddingFit = iota
+	// FitPad zero-fills embeddings shorter than the index's dimension.
+	FitPad
+	// FitTruncate drops the values of embeddings beyond the index's
+	// dimension.
+	FitTruncate
+)
+
+// fitEmbedding applies the client's EmbeddingFit to an embedding, using the
+// dimension given to WithEmbeddingFit as the target. The embedding is
+// returned unchanged if it already fits, if the mode does not apply to its
+// length, or if no dimension was given. The caller's slice is never
+// modified.
+func (c *Client) fitEmbedding(embedding []float32) ([]float32, error) {
+	dim := c.embeddingFitDim
+	if dim <= 0 || len(embedding) == 0 || len(embedding) == dim {
+		return embedding, nil
+	}
+	switch {
+	case c.embeddingFit == FitError:
+		return nil, fmt.Errorf("%w: embedding has %d values, index expects %d", ErrInvalidArgument, len(embedding), dim)
+	case c.embeddingFit == FitPad && len(embedding) < dim:
+		padded := make([]float32, dim)
+		copy(padded, embedding)
+		return padded, nil
+	case c.embeddingFit == FitTruncate && len(embedding) > dim:
+		return append([]float32(nil), embedding[:dim]...), nil
+	}
+	return embedding, nil
+}
@@ -0,0 +1,407 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := CosineSimilarity([]float32{1, 0}, []float32{0, 1}); got != 0 {
+		t.Errorf("Expected orthogonal vectors to score 0, got %v", got)
+	}
+	if got := CosineSimilarity([]float32{1, 2}, []float32{2, 4}); math.Abs(float64(got)-1) > 1e-6 {
+		t.Errorf("Expected parallel vectors to score 1, got %v", got)
+	}
+	if got := CosineSimilarity([]float32{1}, []float32{1, 2}); got != 0 {
+		t.Errorf("Expected mismatched lengths to score 0, got %v", got)
+	}
+}
+
+func embeddingsServer(t *testing.T, stored map[uint64][]float32) *Client {
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/embeddings/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			IDs []uint64 `json:"ids"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		found := map[uint64][]float32{}
+		for _, id := range body.IDs {
+			if emb, ok := stored[id]; ok {
+				found[id] = emb
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"embeddings": found})
+	})
+}
+
+func TestSimilarityMatrix(t *testing.T) {
+	client := embeddingsServer(t, map[uint64][]float32{
+		1: {1, 0, 0},
+		2: {0, 1, 0},
+		3: {1, 1, 0},
+	})
+
+	matrix, err := client.SimilarityMatrix([]uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("SimilarityMatrix failed: %v", err)
+	}
+	if len(matrix) != 3 {
+		t.Fatalf("Expected 3x3 matrix, got %d rows", len(matrix))
+	}
+	for i := range matrix {
+		if matrix[i][i] != 1 {
+			t.Errorf("Expected 1.0 on diagonal at %d, got %v", i, matrix[i][i])
+		}
+		for j := range matrix[i] {
+			if matrix[i][j] != matrix[j][i] {
+				t.Errorf("Expected symmetric matrix, [%d][%d]=%v [%d][%d]=%v", i, j, matrix[i][j], j, i, matrix[j][i])
+			}
+		}
+	}
+	if matrix[0][1] != 0 {
+		t.Errorf("Expected orthogonal nodes to score 0, got %v", matrix[0][1])
+	}
+	if math.Abs(float64(matrix[0][2])-1/math.Sqrt2) > 1e-6 {
+		t.Errorf("Expected %v, got %v", 1/math.Sqrt2, matrix[0][2])
+	}
+}
+
+func TestSimilarityMatrixMissingEmbedding(t *testing.T) {
+	client := embeddingsServer(t, map[uint64][]float32{1: {1, 0}})
+
+	_, err := client.SimilarityMatrix([]uint64{1, 2, 3})
+	if err == nil || !strings.Contains(err.Error(), "[2 3]") {
+		t.Errorf("Expected error naming nodes 2 and 3, got %v", err)
+	}
+}
+
+func TestChunkedSetEmbedding(t *testing.T) {
+	var chunks []embeddingChunk
+	var assembled []float32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/embeddings/chunk" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var chunk embeddingChunk
+		json.NewDecoder(r.Body).Decode(&chunk)
+		if chunk.ChunkIndex != len(chunks) {
+			t.Errorf("Expected chunk %d, got %d", len(chunks), chunk.ChunkIndex)
+		}
+		chunks = append(chunks, chunk)
+		assembled = append(assembled, chunk.Values...)
+		w.WriteHeader(http.StatusOK)
+	}, WithEmbeddingChunkSize(4))
+
+	embedding := make([]float32, 10)
+	for i := range embedding {
+		embedding[i] = float32(i) / 10
+	}
+	if err := client.SetEmbedding(7, embedding); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if chunk.ID != 7 || chunk.TotalChunks != 3 {
+			t.Errorf("unexpected chunk %d header: %+v", i, chunk)
+		}
+	}
+	if len(chunks[2].Values) != 2 {
+		t.Errorf("Expected final chunk of 2 values, got %d", len(chunks[2].Values))
+	}
+	if len(assembled) != len(embedding) {
+		t.Fatalf("Expected %d reassembled values, got %d", len(embedding), len(assembled))
+	}
+	for i := range embedding {
+		if assembled[i] != embedding[i] {
+			t.Errorf("Reassembled value %d = %v, want %v", i, assembled[i], embedding[i])
+		}
+	}
+}
+
+func TestSmallEmbeddingNotChunked(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("Expected a single /embeddings request, got %s", r.URL.Path)
+		}
+	}, WithEmbeddingChunkSize(4))
+
+	if err := client.SetEmbedding(7, []float32{0.1, 0.2, 0.3}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+}
+
+func TestCompareEmbeddings(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/embeddings/compare" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if q.Get("b") == "99" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "node 99 has no embedding"})
+			return
+		}
+		if q.Get("a") != "1" || q.Get("b") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"similarity":0.83,"metric":"dot"}`))
+	})
+
+	sim, err := client.CompareEmbeddings(1, 2)
+	if err != nil {
+		t.Fatalf("CompareEmbeddings failed: %v", err)
+	}
+	if sim != 0.83 {
+		t.Errorf("Expected 0.83, got %v", sim)
+	}
+
+	if _, err := client.CompareEmbeddings(1, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWithDistanceMetric(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["metric"] != MetricL2 {
+			t.Errorf("%s: expected metric l2, got %v", r.URL.Path, body["metric"])
+		}
+		w.Write([]byte(`{"results":[]}`))
+	}, WithDistanceMetric(MetricL2))
+
+	if _, err := client.VectorSearch([]float32{0.1}, 5); err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if _, err := client.HybridQuery(1, []float32{0.1}, 2, 5, DefaultHybridParams()); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+
+	sim, err := client.CosineSimilarity([]float32{1, 0}, []float32{1, 0})
+	if !errors.Is(err, ErrMetricMismatch) {
+		t.Errorf("Expected ErrMetricMismatch warning, got %v", err)
+	}
+	if sim != 1 {
+		t.Errorf("Expected similarity 1 despite the warning, got %v", sim)
+	}
+}
+
+func TestDefaultDistanceMetric(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["metric"]; ok {
+			t.Errorf("Expected no metric by default, got %v", body["metric"])
+		}
+		w.Write([]byte(`{"results":[]}`))
+	})
+
+	if _, err := client.VectorSearch([]float32{0.1}, 5); err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if _, err := client.CosineSimilarity([]float32{1}, []float32{1}); err != nil {
+		t.Errorf("Expected no warning without a configured metric, got %v", err)
+	}
+}
+
+// fitServer records the length of each embedding it receives, for a client
+// fitting embeddings to a 4-dimensional index. It reports a much larger
+// maximum dimension in ServerInfo, which must not be used as the target.
+func fitServer(t *testing.T, mode EmbeddingFit) (*Client, func() []int) {
+	var mu sync.Mutex
+	var lengths []int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			w.Write([]byte(`{"version":"1.0","max_embedding_dim":4096}`))
+			return
+		}
+		var body struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		lengths = append(lengths, len(body.Embedding))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}, WithEmbeddingFit(mode, 4))
+	return client, func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), lengths...)
+	}
+}
+
+func TestWithEmbeddingFitPad(t *testing.T) {
+	client, lengths := fitServer(t, FitPad)
+
+	short := []float32{0.1, 0.2}
+	if err := client.SetEmbedding(1, short); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if err := client.CreateNode(&Node{ID: 2, Embedding: []float32{0.1}}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if err := client.SetEmbedding(3, []float32{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if got := lengths(); len(got) != 3 || got[0] != 4 || got[1] != 4 || got[2] != 5 {
+		t.Errorf("Expected lengths [4 4 5], got %v", got)
+	}
+	if len(short) != 2 {
+		t.Errorf("Expected caller's embedding to be left alone, got %v", short)
+	}
+}
+
+func TestWithEmbeddingFitTruncate(t *testing.T) {
+	client, lengths := fitServer(t, FitTruncate)
+
+	if err := client.SetEmbedding(1, []float32{1, 2, 3, 4, 5, 6}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if err := client.CreateNode(&Node{ID: 2, Embedding: []float32{1, 2, 3, 4, 5}}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if err := client.SetEmbedding(3, []float32{1, 2}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if got := lengths(); len(got) != 3 || got[0] != 4 || got[1] != 4 || got[2] != 2 {
+		t.Errorf("Expected lengths [4 4 2], got %v", got)
+	}
+}
+
+func TestEmbeddingFitDefault(t *testing.T) {
+	client, lengths := fitServer(t, FitError)
+
+	if err := client.SetEmbedding(1, []float32{1, 2, 3, 4}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if got := lengths(); len(got) != 1 || got[0] != 4 {
+		t.Errorf("Expected a fitting embedding sent unchanged, got lengths %v", got)
+	}
+}
+
+func TestEmbeddingFitError(t *testing.T) {
+	client, lengths := fitServer(t, FitError)
+	short := []float32{1, 2}
+
+	calls := map[string]func() error{
+		"SetEmbedding":    func() error { return client.SetEmbedding(1, short) },
+		"UpdateEmbedding": func() error { return client.UpdateEmbedding(1, short, false) },
+		"CreateNode":      func() error { return client.CreateNode(&Node{ID: 1, Embedding: short}) },
+		"CreateNodes":     func() error { return client.CreateNodes([]Node{{ID: 1, Embedding: short}}) },
+		"CreateNodeAutoID": func() error {
+			_, err := client.CreateNodeAutoID(&Node{Embedding: short})
+			return err
+		},
+		"CreateNodeWithEdges": func() error { return client.CreateNodeWithEdges(&Node{ID: 1, Embedding: short}, nil) },
+		"BulkLoader.Load": func() error {
+			return client.NewBulkLoader().Load(context.Background(), []Node{{ID: 1, Embedding: short}})
+		},
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+	if got := lengths(); len(got) != 0 {
+		t.Errorf("Expected no requests, got lengths %v", got)
+	}
+}
+
+func TestEmbeddingFitWithoutDimension(t *testing.T) {
+	var sent int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Embedding []float32 `json:"embedding"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sent = len(body.Embedding)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.SetEmbedding(1, []float32{1, 2}); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("Expected the embedding sent unchanged, got length %d", sent)
+	}
+}
+
+func TestEmbeddingNorm(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/embeddings/1":
+			w.Write([]byte(`{"id":1,"embedding":[3,4]}`))
+		case "/embeddings/2":
+			w.Write([]byte(`{"id":2,"embedding":[]}`))
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no embedding"})
+		}
+	})
+
+	norm, err := client.EmbeddingNorm(1)
+	if err != nil {
+		t.Fatalf("EmbeddingNorm failed: %v", err)
+	}
+	if norm != 5 {
+		t.Errorf("Expected norm 5, got %v", norm)
+	}
+	for _, id := range []uint64{2, 3} {
+		if _, err := client.EmbeddingNorm(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("node %d: expected ErrNotFound, got %v", id, err)
+		}
+	}
+}
+
+// laggingIndexServer stores node 5's embedding immediately but only
+// returns it from vector search from the indexedAfter'th search on.
+func laggingIndexServer(t *testing.T, indexedAfter int32) (*Client, *int32) {
+	var searches int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/embeddings/5":
+			w.Write([]byte(`{"id":5,"embedding":[0.6,0.8]}`))
+		case "/query/vector":
+			if atomic.AddInt32(&searches, 1) < indexedAfter {
+				w.Write([]byte(`{"results":[{"id":2,"score":0.4}]}`))
+				return
+			}
+			w.Write([]byte(`{"results":[{"id":5,"score":1},{"id":2,"score":0.4}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	return client, &searches
+}
+
+func TestWaitForIndexed(t *testing.T) {
+	client, searches := laggingIndexServer(t, 3)
+
+	if err := client.WaitForIndexed(5, 5*time.Second); err != nil {
+		t.Fatalf("WaitForIndexed failed: %v", err)
+	}
+	if got := atomic.LoadInt32(searches); got != 3 {
+		t.Errorf("Expected 3 searches, got %d", got)
+	}
+}
+
+func TestWaitForIndexedTimeout(t *testing.T) {
+	client, _ := laggingIndexServer(t, 1000)
+
+	err := client.WaitForIndexed(5, 120*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+}
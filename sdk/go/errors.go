@@ -0,0 +1,32 @@
+package barqgraphdb
+
+import "errors"
+
+var (
+	// ErrNoPath is returned when no path exists between two nodes.
+	ErrNoPath = errors.New("barqgraphdb: no path between nodes")
+
+	// ErrUnsupportedFeature is returned when a method relies on a feature
+	// the server does not advertise in its ServerInfo.
+	ErrUnsupportedFeature = errors.New("barqgraphdb: feature not supported by server")
+
+	// ErrNotFound is returned when a requested resource does not exist.
+	// Server responses with status 404 match it via errors.Is.
+	ErrNotFound = errors.New("barqgraphdb: not found")
+
+	// ErrInvalidArgument is returned when a method is called with an
+	// argument the client can reject before contacting the server.
+	ErrInvalidArgument = errors.New("barqgraphdb: invalid argument")
+
+	// ErrConflict is returned when an operation would duplicate an existing
+	// resource. Server responses with status 409 match it via errors.Is.
+	ErrConflict = errors.New("barqgraphdb: conflict with existing resource")
+
+	// ErrWriterClosed is returned when adding to a NodeBatchWriter after
+	// Close.
+	ErrWriterClosed = errors.New("barqgraphdb: batch writer is closed")
+
+	// ErrMetricMismatch warns that a locally computed similarity uses a
+	// different metric than the server configured with WithDistanceMetric.
+	ErrMetricMismatch = errors.New("barqgraphdb: similarity metric differs from server metric")
+)
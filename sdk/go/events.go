@@ -0,0 +1,155 @@
+package barqgraphdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultReconnectBackoff is the first delay before reconnecting a
+	// dropped subscription; it doubles on each failed attempt.
+	defaultReconnectBackoff = 500 * time.Millisecond
+
+	// maxReconnectBackoff caps the delay between reconnection attempts.
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// Event is a server-sent event delivered by Subscribe.
+type Event struct {
+	ID   string
+	Type string
+	Data json.RawMessage
+}
+
+// Subscribe streams server-sent events from GET /events. The events channel
+// is closed when the stream ends or ctx is cancelled; at most one error is
+// sent on the error channel, which is closed after the events channel.
+// Cancelling ctx is the normal way to end a subscription and is not
+// reported as an error.
+//
+// With WithAutoReconnect a dropped stream is reopened with exponential
+// backoff, sending the last event ID in the Last-Event-ID header so the
+// server can resume where it left off. Only client errors (4xx) end such a
+// subscription.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		lastID := ""
+		backoff := c.reconnectBackoff
+		for {
+			received, err := c.subscribeOnce(ctx, lastID, func(e Event) error {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				if e.ID != "" {
+					lastID = e.ID
+				}
+				return nil
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if !c.autoReconnect || isClientError(err) {
+				if err != nil {
+					errs <- err
+				}
+				return
+			}
+
+			if received {
+				backoff = c.reconnectBackoff
+			}
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			backoff = min(2*backoff, maxReconnectBackoff)
+		}
+	}()
+
+	return events, errs
+}
+
+// subscribeOnce opens the event stream once and emits its events until it
+// ends, reporting whether any event was received.
+func (c *Client) subscribeOnce(ctx context.Context, lastID string, emit func(Event) error) (bool, error) {
+	header := http.Header{}
+	header.Set("Accept", "text/event-stream")
+	if lastID != "" {
+		header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := c.openStream(ctx, "GET", "/events", nil, header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	received := false
+	err = decodeEvents(resp.Body, func(e Event) error {
+		received = true
+		return emit(e)
+	})
+	return received, err
+}
+
+// decodeEvents parses a text/event-stream body, calling emit for each
+// complete event. Comments and unknown fields are ignored.
+func decodeEvents(r io.Reader, emit func(Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var event Event
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 {
+				event.Data = json.RawMessage(strings.Join(data, "\n"))
+				if err := emit(event); err != nil {
+					return err
+				}
+			}
+			event, data = Event{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil
+}
+
+// isClientError reports whether err is an API error with a 4xx status,
+// which retrying will not fix.
+func isClientError(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
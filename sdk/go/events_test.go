@@ -0,0 +1,107 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/events" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Expected Accept text/event-stream, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "id: 1\nevent: node_created\ndata: {\"id\":10}\n\n")
+		fmt.Fprint(w, "id: 2\nevent: edge_created\ndata: {\"from\":10,\ndata: \"to\":11}\n\n")
+	})
+
+	events, errs := client.Subscribe(context.Background())
+	var got []Event
+	for e := range events {
+		got = append(got, e)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 events, got %+v", got)
+	}
+	if got[0].ID != "1" || got[0].Type != "node_created" || string(got[0].Data) != `{"id":10}` {
+		t.Errorf("unexpected first event %+v", got[0])
+	}
+	if string(got[1].Data) != "{\"from\":10,\n\"to\":11}" {
+		t.Errorf("Expected multi-line data joined with newlines, got %q", got[1].Data)
+	}
+}
+
+func TestSubscribeAutoReconnect(t *testing.T) {
+	var mu sync.Mutex
+	var lastIDs []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		attempt := len(lastIDs)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		switch attempt {
+		case 1:
+			fmt.Fprint(w, "id: 1\ndata: {}\n\nid: 2\ndata: {}\n\n")
+			// Returning drops the connection.
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, "id: 3\ndata: {}\n\n")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}, WithAutoReconnect())
+	client.reconnectBackoff = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, errs := client.Subscribe(ctx)
+	var ids []string
+	for e := range events {
+		ids = append(ids, e.ID)
+		if len(ids) == 3 {
+			cancel()
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("Expected no error after cancelling, got %v", err)
+	}
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("Expected events 1,2,3, got %v", ids)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastIDs) != 3 || lastIDs[0] != "" || lastIDs[1] != "2" || lastIDs[2] != "2" {
+		t.Errorf("Expected Last-Event-ID [\"\" 2 2], got %q", lastIDs)
+	}
+}
+
+func TestSubscribeClientErrorStopsReconnecting(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+	}, WithAutoReconnect())
+	client.reconnectBackoff = time.Millisecond
+
+	events, errs := client.Subscribe(context.Background())
+	for range events {
+		t.Error("Expected no events")
+	}
+	if err := <-errs; !isClientError(err) {
+		t.Errorf("Expected a 401 error, got %v", err)
+	}
+}
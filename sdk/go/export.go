@@ -0,0 +1,128 @@
+package barqgraphdb
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// exportPageSize is the number of records fetched per request while
+// exporting.
+const exportPageSize = 500
+
+// ExportDecisions writes every decision recorded by an agent to w as
+// newline-delimited JSON, one Decision per line. Decisions are fetched page
+// by page so memory use stays flat for large logs.
+func (c *Client) ExportDecisions(agentID uint64, w io.Writer) error {
+	return c.ExportDecisionsFiltered(agentID, DecisionFilter{}, w)
+}
+
+// DecisionFilter selects decisions for ExportDecisionsFiltered. Zero-valued
+// fields do not filter; a decision must satisfy every field that is set.
+type DecisionFilter struct {
+	// MinScore is the lowest score to include.
+	MinScore float32
+	// Since and Until bound CreatedAt, inclusive. Decisions without a
+	// creation time are excluded when either is set.
+	Since time.Time
+	Until time.Time
+	// Match, if set, is called for each decision passing the other fields.
+	Match func(d *Decision) bool
+}
+
+func (f *DecisionFilter) matches(d *Decision) bool {
+	if f.MinScore != 0 && d.Score < f.MinScore {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		if d.CreatedAt == nil {
+			return false
+		}
+		created := time.Unix(int64(*d.CreatedAt), 0)
+		if !f.Since.IsZero() && created.Before(f.Since) {
+			return false
+		}
+		if !f.Until.IsZero() && created.After(f.Until) {
+			return false
+		}
+	}
+	return f.Match == nil || f.Match(d)
+}
+
+// ExportDecisionsFiltered is ExportDecisions writing only the decisions
+// that match filter, such as recent high-score decisions for an audit.
+// Like AllNodes, it stops after a page larger than requested and fails if
+// the server repeats a page instead of advancing.
+func (c *Client) ExportDecisionsFiltered(agentID uint64, filter DecisionFilter, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	var prev []Decision
+	for offset := 0; ; offset += exportPageSize {
+		page, err := c.ListDecisionsPaged(agentID, offset, exportPageSize)
+		if err != nil {
+			return err
+		}
+		if err := checkPage(prev, page, offset); err != nil {
+			return err
+		}
+		for i := range page {
+			if !filter.matches(&page[i]) {
+				continue
+			}
+			if err := enc.Encode(&page[i]); err != nil {
+				return err
+			}
+		}
+		if len(page) != exportPageSize {
+			return nil
+		}
+		prev = page
+	}
+}
+
+// ExportDOT writes the whole graph to w in Graphviz DOT format, with nodes
+// labelled by their label and edges by their type, ready for rendering with
+// dot. Nodes and edges are fetched page by page and streamed as they
+// arrive.
+func (c *Client) ExportDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := io.WriteString(bw, "digraph barq {\n"); err != nil {
+		return err
+	}
+	for node, err := range c.AllNodes(exportPageSize) {
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(bw, "  %d [label=%s];\n", node.ID, dotQuote(node.Label)); err != nil {
+			return err
+		}
+	}
+	cursor := ""
+	for {
+		edges, next, err := c.ListEdgesCursor(cursor, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range edges {
+			if _, err := fmt.Fprintf(bw, "  %d -> %d [label=%s];\n", e.From, e.To, dotQuote(e.EdgeType)); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if _, err := io.WriteString(bw, "}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// dotQuote returns s as a DOT double-quoted string.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,208 @@
+package barqgraphdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decisionsServer serves n decisions for agent 42 with offset/limit paging.
+// Decision i (from 0) scores (i%10)/10 and was created at decisionEpoch+i.
+const decisionEpoch = 1700000000
+
+func decisionsServer(t *testing.T, n int) (*Client, *int) {
+	requests := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		q := r.URL.Query()
+		if r.URL.Path != "/decisions" || q.Get("agent_id") != "42" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		decisions := []Decision{}
+		for i := offset; i < n && i < offset+limit; i++ {
+			id := uint64(i + 1)
+			created := uint64(decisionEpoch + i)
+			decisions = append(decisions, Decision{ID: &id, AgentID: 42, RootNode: 1, Path: []uint64{1},
+				Score: float32(i%10) / 10, CreatedAt: &created})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"decisions": decisions})
+	})
+	return client, &requests
+}
+
+func TestExportDecisions(t *testing.T) {
+	total := exportPageSize + 3
+	client, requests := decisionsServer(t, total)
+
+	var buf bytes.Buffer
+	if err := client.ExportDecisions(42, &buf); err != nil {
+		t.Fatalf("ExportDecisions failed: %v", err)
+	}
+	if *requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", *requests)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var d Decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			t.Fatalf("line %d is not a valid Decision: %v", lines+1, err)
+		}
+		lines++
+		if d.ID == nil || *d.ID != uint64(lines) || d.AgentID != 42 {
+			t.Errorf("unexpected decision on line %d: %+v", lines, d)
+		}
+	}
+	if lines != total {
+		t.Errorf("Expected %d lines, got %d", total, lines)
+	}
+}
+
+func TestExportDecisionsUnpagedServer(t *testing.T) {
+	// The server ignores offset and limit and returns all decisions.
+	for _, total := range []int{exportPageSize, exportPageSize + 3} {
+		requests := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			decisions := make([]Decision, total)
+			for i := range decisions {
+				id := uint64(i + 1)
+				decisions[i] = Decision{ID: &id, AgentID: 42}
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"decisions": decisions})
+		})
+
+		var buf bytes.Buffer
+		err := client.ExportDecisions(42, &buf)
+		lines := strings.Count(buf.String(), "\n")
+		if total == exportPageSize {
+			// A full page repeated cannot be told from a real second page
+			// until it arrives, so the export fails after the first page.
+			if err == nil || requests != 2 || lines != total {
+				t.Errorf("Expected an error after %d lines and 2 requests, got %v after %d lines and %d requests", total, err, lines, requests)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ExportDecisions failed: %v", err)
+		}
+		if requests != 1 || lines != total {
+			t.Errorf("Expected %d lines from 1 request, got %d from %d", total, lines, requests)
+		}
+	}
+}
+
+func TestExportDecisionsFiltered(t *testing.T) {
+	total := exportPageSize + 30
+	client, _ := decisionsServer(t, total)
+
+	filter := DecisionFilter{
+		MinScore: 0.8,
+		Since:    time.Unix(decisionEpoch+exportPageSize, 0),
+	}
+	var buf bytes.Buffer
+	if err := client.ExportDecisionsFiltered(42, filter, &buf); err != nil {
+		t.Fatalf("ExportDecisionsFiltered failed: %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var d Decision
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			t.Fatalf("line %d is not a valid Decision: %v", lines+1, err)
+		}
+		lines++
+		if d.Score < 0.8 || *d.CreatedAt < decisionEpoch+exportPageSize {
+			t.Errorf("decision %d should have been filtered out: %+v", *d.ID, d)
+		}
+	}
+	// Of the last 30 decisions, those with i%10 of 8 or 9 qualify.
+	if lines != 6 {
+		t.Errorf("Expected 6 matching decisions, got %d", lines)
+	}
+}
+
+func TestExportDecisionsFilteredMatch(t *testing.T) {
+	client, _ := decisionsServer(t, 20)
+
+	filter := DecisionFilter{Match: func(d *Decision) bool { return *d.ID%5 == 0 }}
+	var buf bytes.Buffer
+	if err := client.ExportDecisionsFiltered(42, filter, &buf); err != nil {
+		t.Fatalf("ExportDecisionsFiltered failed: %v", err)
+	}
+	if got := bytes.Count(buf.Bytes(), []byte("\n")); got != 4 {
+		t.Errorf("Expected 4 decisions, got %d", got)
+	}
+}
+
+func TestExportDOT(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nodes":
+			if r.URL.Query().Get("offset") != "0" {
+				writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": []Node{}})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": []Node{
+				{ID: 1, Label: `Say "hi"`},
+				{ID: 2, Label: `back\slash`},
+				{ID: 3, Label: "plain"},
+			}})
+		case "/edges":
+			if r.URL.Query().Get("cursor") == "" {
+				writeJSON(w, http.StatusOK, map[string]interface{}{
+					"edges":       []Edge{{From: 1, To: 2, EdgeType: "OWNS"}},
+					"next_cursor": "next",
+				})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"edges": []Edge{{From: 2, To: 3, EdgeType: `"quoted"`}},
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	var buf bytes.Buffer
+	if err := client.ExportDOT(&buf); err != nil {
+		t.Fatalf("ExportDOT failed: %v", err)
+	}
+	out := buf.String()
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if lines[0] != "digraph barq {" || lines[len(lines)-1] != "}" {
+		t.Fatalf("Expected a digraph block, got:\n%s", out)
+	}
+	nodeLine := regexp.MustCompile(`^  \d+ \[label="(?:[^"\\]|\\.)*"\];$`)
+	edgeLine := regexp.MustCompile(`^  \d+ -> \d+ \[label="(?:[^"\\]|\\.)*"\];$`)
+	nodes, edges := 0, 0
+	for _, line := range lines[1 : len(lines)-1] {
+		switch {
+		case edgeLine.MatchString(line):
+			edges++
+		case nodeLine.MatchString(line):
+			nodes++
+		default:
+			t.Errorf("Invalid DOT statement %q", line)
+		}
+	}
+	if nodes != 3 || edges != 2 {
+		t.Errorf("Expected 3 nodes and 2 edges, got %d and %d", nodes, edges)
+	}
+	for _, want := range []string{`1 [label="Say \"hi\""]`, `2 [label="back\\slash"]`, `2 -> 3 [label="\"quoted\""]`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %s, got:\n%s", want, out)
+		}
+	}
+}
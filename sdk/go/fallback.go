@@ -0,0 +1,93 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FallbackOption configures how HybridQueryWithFallback recovers from an
+// empty result.
+type FallbackOption func(*fallbackPolicy)
+
+type fallbackPolicy struct {
+	maxHopsCap   int
+	vectorSearch bool
+	budget       time.Duration
+}
+
+// WidenHops retries an empty hybrid query with one more hop at a time, up to
+// maxHopsCap hops or the client's WithMaxHopsCeiling, whichever is lower.
+func WidenHops(maxHopsCap int) FallbackOption {
+	return func(p *fallbackPolicy) {
+		p.maxHopsCap = maxHopsCap
+	}
+}
+
+// FallbackToVectorSearch runs a pure VectorSearch for k results when the
+// hybrid query (including any widened retries) finds nothing. Set enabled to
+// false to return the empty result instead.
+func FallbackToVectorSearch(enabled bool) FallbackOption {
+	return func(p *fallbackPolicy) {
+		p.vectorSearch = enabled
+	}
+}
+
+// FallbackBudget bounds the total time spent on the initial query and every
+// fallback attempt together. Once it is used up no further attempts are
+// made, and an attempt still in flight is canceled.
+func FallbackBudget(budget time.Duration) FallbackOption {
+	return func(p *fallbackPolicy) {
+		p.budget = budget
+	}
+}
+
+// HybridQueryWithFallback runs HybridQuery and, if it returns no results,
+// tries to find some anyway, which helps recall on sparse graphs. By default
+// it falls back to VectorSearch; WidenHops first retries with larger
+// maxHops. The vector fallback is skipped when queryEmbedding is empty.
+// With FallbackBudget, running out of time returns the empty result so far
+// together with an error wrapping context.DeadlineExceeded.
+func (c *Client) HybridQueryWithFallback(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams, opts ...FallbackOption) ([]HybridResult, error) {
+	policy := fallbackPolicy{vectorSearch: true}
+	for _, opt := range opts {
+		opt(&policy)
+	}
+
+	ctx := context.Background()
+	if policy.budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.budget)
+		defer cancel()
+	}
+	exhausted := func() error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("fallback budget of %v exhausted: %w", policy.budget, err)
+		}
+		return nil
+	}
+
+	results, err := c.HybridQueryContext(ctx, start, queryEmbedding, maxHops, k, params)
+	if err != nil || len(results) > 0 {
+		return results, err
+	}
+	if c.maxHopsCeiling > 0 && policy.maxHopsCap > c.maxHopsCeiling {
+		policy.maxHopsCap = c.maxHopsCeiling
+	}
+	for hops := maxHops + 1; hops <= policy.maxHopsCap; hops++ {
+		if err := exhausted(); err != nil {
+			return results, err
+		}
+		results, err = c.HybridQueryContext(ctx, start, queryEmbedding, hops, k, params)
+		if err != nil || len(results) > 0 {
+			return results, err
+		}
+	}
+	if policy.vectorSearch && len(queryEmbedding) > 0 {
+		if err := exhausted(); err != nil {
+			return results, err
+		}
+		return c.vectorSearch(ctx, VectorSearchRequest{QueryEmbedding: queryEmbedding, K: k, Exact: params.Exact})
+	}
+	return results, nil
+}
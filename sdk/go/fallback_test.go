@@ -0,0 +1,142 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sparseGraphServer returns hybrid results only once max_hops reaches
+// reachableAt, and serves vector search results. It records the hop counts
+// and endpoints requested.
+func sparseGraphServer(t *testing.T, reachableAt int) (*Client, func() []string) {
+	var mu sync.Mutex
+	var calls []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body HybridQueryRequest
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/query/hybrid":
+			calls = append(calls, "hybrid")
+			if body.MaxHops >= reachableAt {
+				w.Write([]byte(`{"results":[{"id":9,"score":0.5}]}`))
+				return
+			}
+			w.Write([]byte(`{"results":[]}`))
+		case "/query/vector":
+			calls = append(calls, "vector")
+			w.Write([]byte(`{"results":[{"id":4,"score":0.8}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+}
+
+func TestHybridQueryWithFallbackVectorSearch(t *testing.T) {
+	client, calls := sparseGraphServer(t, 100)
+
+	results, err := client.HybridQueryWithFallback(1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	if err != nil {
+		t.Fatalf("HybridQueryWithFallback failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 4 {
+		t.Errorf("Expected vector search result 4, got %+v", results)
+	}
+	if got := calls(); len(got) != 2 || got[1] != "vector" {
+		t.Errorf("Expected hybrid then vector, got %v", got)
+	}
+}
+
+func TestHybridQueryWithFallbackWidenHops(t *testing.T) {
+	client, calls := sparseGraphServer(t, 4)
+
+	results, err := client.HybridQueryWithFallback(1, []float32{0.1}, 2, 5, DefaultHybridParams(), WidenHops(5))
+	if err != nil {
+		t.Fatalf("HybridQueryWithFallback failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 9 {
+		t.Errorf("Expected hybrid result 9, got %+v", results)
+	}
+	if got := calls(); len(got) != 3 {
+		t.Errorf("Expected hybrid queries at 2, 3 and 4 hops, got %v", got)
+	}
+}
+
+func TestHybridQueryWithFallbackDisabled(t *testing.T) {
+	client, calls := sparseGraphServer(t, 100)
+
+	results, err := client.HybridQueryWithFallback(1, []float32{0.1}, 2, 5, DefaultHybridParams(),
+		WidenHops(3), FallbackToVectorSearch(false))
+	if err != nil {
+		t.Fatalf("HybridQueryWithFallback failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+	if got := calls(); len(got) != 2 || got[1] != "hybrid" {
+		t.Errorf("Expected two hybrid queries and no vector search, got %v", got)
+	}
+}
+
+func TestHybridQueryWithFallbackBudget(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.URL.Path)
+		mu.Unlock()
+		if r.URL.Path == "/query/vector" {
+			w.Write([]byte(`{"results":[{"id":4,"score":0.8}]}`))
+			return
+		}
+		time.Sleep(30 * time.Millisecond)
+		w.Write([]byte(`{"results":[]}`))
+	})
+
+	results, err := client.HybridQueryWithFallback(1, []float32{0.1}, 2, 5, DefaultHybridParams(),
+		WidenHops(20), FallbackBudget(100*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected budget to run out with DeadlineExceeded, got %v (results %+v)", err, results)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %+v", results)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) >= 19 {
+		t.Errorf("Expected the budget to cut off widening before 20 hops, got %d attempts", len(calls))
+	}
+	for _, path := range calls {
+		if path == "/query/vector" {
+			t.Errorf("Expected no vector search after the budget ran out, got %v", calls)
+		}
+	}
+}
+
+func TestHybridQueryWithFallbackWithinBudget(t *testing.T) {
+	client, calls := sparseGraphServer(t, 100)
+
+	results, err := client.HybridQueryWithFallback(1, []float32{0.1}, 2, 5, DefaultHybridParams(),
+		WidenHops(3), FallbackBudget(5*time.Second))
+	if err != nil {
+		t.Fatalf("HybridQueryWithFallback failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 4 {
+		t.Errorf("Expected vector search result 4, got %+v", results)
+	}
+	if got := calls(); len(got) != 3 {
+		t.Errorf("Expected two hybrid queries and a vector search, got %v", got)
+	}
+}
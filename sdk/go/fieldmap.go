@@ -0,0 +1,48 @@
+package barqgraphdb
+
+// dataMapFields are the fields whose values are objects keyed by data, such
+// as labels, tags or node IDs, rather than by field names. Their keys are
+// never renamed, so a label that happens to match a mapped field survives.
+var dataMapFields = map[string]bool{
+	"labels":       true,
+	"cooccurrence": true,
+	"edge_types":   true,
+	"exists":       true,
+	"scores":       true,
+	"communities":  true,
+	"embeddings":   true,
+}
+
+// renameFields rewrites the keys of every JSON object in data according to
+// names, except inside dataMapFields. Numbers are preserved exactly so large
+// IDs survive the round trip. Data that is not valid JSON is returned
+// unchanged.
+func renameFields(data []byte, names map[string]string) []byte {
+	return transformJSON(data, func(v interface{}) interface{} {
+		return renameValue(v, names)
+	})
+}
+
+func renameValue(v interface{}, names map[string]string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			if renamed, ok := names[key]; ok {
+				key = renamed
+			}
+			if _, isMap := val.(map[string]interface{}); isMap && dataMapFields[key] {
+				out[key] = val
+				continue
+			}
+			out[key] = renameValue(val, names)
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = renameValue(v[i], names)
+		}
+		return v
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestWithFieldMap(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/nodes":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["id"]; ok {
+				t.Errorf("Expected id to be renamed, got %v", body)
+			}
+			if body["node_id"] != float64(7) {
+				t.Errorf("Expected node_id 7, got %v", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/nodes/18446744073709551615":
+			w.Write([]byte(`{"node_id":18446744073709551615,"label":"Max"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithFieldMap(map[string]string{"id": "node_id"}))
+
+	if err := client.CreateNode(&Node{ID: 7, Label: "User"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+
+	node, err := client.GetNode(18446744073709551615)
+	if err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if node.ID != 18446744073709551615 || node.Label != "Max" {
+		t.Errorf("Expected node_id mapped back to ID without precision loss, got %+v", node)
+	}
+}
+
+func TestRenameFieldsNested(t *testing.T) {
+	in := []byte(`{"nodes":[{"id":1,"rule_tags":["id"]},{"id":2}],"count":2}`)
+	out := renameFields(in, map[string]string{"id": "node_id"})
+
+	var got struct {
+		Nodes []map[string]interface{} `json:"nodes"`
+	}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("invalid output %s: %v", out, err)
+	}
+	for i, n := range got.Nodes {
+		if _, ok := n["node_id"]; !ok {
+			t.Errorf("Expected node %d to be renamed, got %v", i, n)
+		}
+	}
+	if tags := got.Nodes[0]["rule_tags"].([]interface{}); tags[0] != "id" {
+		t.Errorf("Expected string values to be left alone, got %v", tags)
+	}
+}
+
+func TestWithFieldMapLeavesDataKeysAlone(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nodes/labels":
+			w.Write([]byte(`{"labels":{"node_id":2,"User":1}}`))
+		case "/nodes/tags/cooccurrence":
+			w.Write([]byte(`{"cooccurrence":{"node_id":{"admin":3},"admin":{"node_id":3}}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithFieldMap(map[string]string{"id": "node_id"}))
+
+	labels, err := client.ListLabels()
+	if err != nil {
+		t.Fatalf("ListLabels failed: %v", err)
+	}
+	if labels["node_id"] != 2 || labels["User"] != 1 || len(labels) != 2 {
+		t.Errorf("Expected label node_id to keep its name, got %v", labels)
+	}
+
+	counts, err := client.TagCooccurrence()
+	if err != nil {
+		t.Fatalf("TagCooccurrence failed: %v", err)
+	}
+	if counts["node_id"]["admin"] != 3 || counts["admin"]["node_id"] != 3 {
+		t.Errorf("Expected tag node_id to keep its name, got %v", counts)
+	}
+}
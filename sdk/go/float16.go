@@ -0,0 +1,103 @@
+package barqgraphdb
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// float16Codec sends embeddings as base64 of their little-endian IEEE 754
+// half-precision bytes, in "<field>_f16" fields.
+var float16Codec = &embeddingCodec{
+	suffix: "_f16",
+	encode: encodeEmbeddingFloat16,
+	decode: decodeEmbeddingFloat16,
+}
+
+// Float32ToFloat16 converts f to IEEE 754 half precision, rounding to the
+// nearest representable value (ties to even). Values beyond ±65504 become
+// infinities and values below about 6e-8 in magnitude become zero.
+func Float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			return sign | 0x7e00 // NaN
+		}
+		return sign | 0x7c00 // infinity
+	}
+
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+	if e <= 0 {
+		// Subnormal half: the value is m * 2^-24.
+		if e < -10 {
+			return sign
+		}
+		full := mant | 0x800000
+		shift := uint(14 - e)
+		m := full >> shift
+		rem := full & (1<<shift - 1)
+		half := uint32(1) << (shift - 1)
+		if rem > half || (rem == half && m&1 == 1) {
+			m++
+		}
+		return sign | uint16(m)
+	}
+
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+		h++ // a carry into the exponent is still correctly rounded
+	}
+	return sign | uint16(h)
+}
+
+// Float16ToFloat32 converts an IEEE 754 half-precision value to float32.
+// The conversion is exact.
+func Float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			v = -v
+		}
+		return v
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+}
+
+func encodeEmbeddingFloat16(embedding []float32) string {
+	buf := make([]byte, 2*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint16(buf[2*i:], Float32ToFloat16(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func decodeEmbeddingFloat16(s string) ([]float32, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid float16 embedding: %w", err)
+	}
+	if len(buf)%2 != 0 {
+		return nil, fmt.Errorf("invalid float16 embedding: %d bytes is not a whole number of float16s", len(buf))
+	}
+	embedding := make([]float32, len(buf)/2)
+	for i := range embedding {
+		embedding[i] = Float16ToFloat32(binary.LittleEndian.Uint16(buf[2*i:]))
+	}
+	return embedding, nil
+}
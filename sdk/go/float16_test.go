@@ -0,0 +1,84 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+)
+
+func TestFloat16RoundTrip(t *testing.T) {
+	values := []float32{0.1, -0.5, 0.33333, 0.9999, -0.0001, 1, 2.5, 1000.25, 60000}
+	for _, v := range values {
+		got := Float16ToFloat32(Float32ToFloat16(v))
+		if rel := math.Abs(float64(got-v)) / math.Abs(float64(v)); rel > 1.0/2048 {
+			t.Errorf("%v: round trip gave %v (relative error %g)", v, got, rel)
+		}
+	}
+}
+
+func TestFloat16SpecialValues(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{65504, 0x7bff},
+		{1e6, 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		{5.960464477539063e-08, 0x0001}, // smallest subnormal
+		{1e-10, 0x0000},
+		{1.00048828125, 0x3c00}, // tie rounds to even
+	}
+	for _, tt := range tests {
+		if got := Float32ToFloat16(tt.in); got != tt.want {
+			t.Errorf("Float32ToFloat16(%v) = %#04x, want %#04x", tt.in, got, tt.want)
+		}
+	}
+	if got := Float16ToFloat32(0x0001); got != 5.960464477539063e-08 {
+		t.Errorf("Expected smallest subnormal, got %v", got)
+	}
+	if got := Float16ToFloat32(Float32ToFloat16(float32(math.NaN()))); !math.IsNaN(float64(got)) {
+		t.Errorf("Expected NaN, got %v", got)
+	}
+}
+
+func TestWithEmbeddingPrecision(t *testing.T) {
+	embedding := []float32{0.12, -0.75, 0.5}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/embeddings":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			s, _ := body["embedding_f16"].(string)
+			got, err := decodeEmbeddingFloat16(s)
+			if err != nil || len(got) != 3 || got[1] != -0.75 {
+				t.Errorf("Expected float16 embedding, got %v (%v)", body, err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/embeddings/1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"id":            1,
+				"embedding_f16": encodeEmbeddingFloat16(embedding),
+			})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithEmbeddingPrecision(16))
+
+	if err := client.SetEmbedding(1, embedding); err != nil {
+		t.Fatalf("SetEmbedding failed: %v", err)
+	}
+	got, err := client.GetEmbedding(1)
+	if err != nil {
+		t.Fatalf("GetEmbedding failed: %v", err)
+	}
+	for i := range embedding {
+		if math.Abs(float64(got[i]-embedding[i])) > 1e-3 {
+			t.Errorf("Value %d: expected about %v, got %v", i, embedding[i], got[i])
+		}
+	}
+}
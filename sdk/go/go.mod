@@ -1,6 +1,6 @@
 module github.com/YASSERRMD/barq-graphdb/sdk/go
 
-go 1.21
+go 1.24
 
 require (
 	google.golang.org/grpc v1.60.0
@@ -0,0 +1,33 @@
+package barqgraphdb
+
+import (
+	"context"
+	"net/http"
+)
+
+type headersKey struct{}
+
+// ContextWithHeaders returns a copy of ctx carrying extra HTTP headers for
+// the requests made with it, such as trace baggage or feature flags for a
+// single call. Headers already attached to ctx are kept unless header sets
+// the same key. Headers the client manages itself, like Content-Type,
+// X-Tenant-ID, X-Request-ID and Idempotency-Key, take precedence. Pass ctx
+// to a *Context method such as GetNodeContext or HybridQueryContext, or to
+// Do, for the headers to be sent.
+func ContextWithHeaders(ctx context.Context, header http.Header) context.Context {
+	merged := headersFromContext(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for key, values := range header {
+		merged[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// headersFromContext returns the headers attached by ContextWithHeaders, or
+// nil if there are none.
+func headersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headersKey{}).(http.Header)
+	return header
+}
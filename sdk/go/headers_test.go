@@ -0,0 +1,106 @@
+package barqgraphdb
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestContextWithHeaders(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Get("X-Feature-Flag") + "|" + r.Header.Get("Baggage")
+		mu.Unlock()
+		if r.Method == "GET" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": 1, "label": "n"})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ctx := ContextWithHeaders(context.Background(), http.Header{"X-Feature-Flag": {"beta"}})
+	ctx = ContextWithHeaders(ctx, http.Header{"baggage": {"tenant=7"}})
+	if err := client.CreateNodesContext(ctx, []Node{{ID: 1, Label: "n"}}); err != nil {
+		t.Fatalf("CreateNodesContext failed: %v", err)
+	}
+	if _, err := client.GetNode(1); err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+
+	if got := seen["POST /nodes/batch"]; got != "beta|tenant=7" {
+		t.Errorf("Expected per-call headers on the batch request, got %q", got)
+	}
+	if got := seen["GET /nodes/1"]; got != "|" {
+		t.Errorf("Expected no per-call headers on a later call, got %q", got)
+	}
+}
+
+func TestContextWithHeadersDoesNotOverrideClientHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	}, WithRequestIDGenerator(func() string { return "client-id" }), WithTenant("acme"))
+
+	ctx := ContextWithHeaders(context.Background(), http.Header{
+		"X-Request-Id": {"caller-id"},
+		"X-Tenant-Id":  {"other"},
+		"Content-Type": {"text/plain"},
+	})
+	if err := client.CreateNodesContext(ctx, []Node{{ID: 1}}); err != nil {
+		t.Fatalf("CreateNodesContext failed: %v", err)
+	}
+	if id := got.Get("X-Request-ID"); id != "client-id" {
+		t.Errorf("Expected X-Request-ID client-id, got %q", id)
+	}
+	if tenant := got.Values("X-Tenant-ID"); len(tenant) != 1 || tenant[0] != "acme" {
+		t.Errorf("Expected X-Tenant-ID acme, got %q", tenant)
+	}
+	if ct := got.Values("Content-Type"); len(ct) != 1 || ct[0] != "application/json" {
+		t.Errorf("Expected Content-Type application/json, got %q", ct)
+	}
+}
+
+func TestContextWithHeadersReachesContextMethods(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Get("X-Feature-Flag")
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/nodes/1":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"id": 1, "label": "n"})
+		case "/query/hybrid", "/query/vector":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"results": []interface{}{}})
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	})
+
+	ctx := ContextWithHeaders(context.Background(), http.Header{"X-Feature-Flag": {"beta"}})
+	if err := client.CreateNodeContext(ctx, &Node{ID: 1, Label: "n"}); err != nil {
+		t.Fatalf("CreateNodeContext failed: %v", err)
+	}
+	if _, err := client.GetNodeContext(ctx, 1); err != nil {
+		t.Fatalf("GetNodeContext failed: %v", err)
+	}
+	if _, err := client.HybridQueryContext(ctx, 1, []float32{1}, 2, 5, HybridParams{Alpha: 0.5, Beta: 0.5}); err != nil {
+		t.Fatalf("HybridQueryContext failed: %v", err)
+	}
+	if _, err := client.VectorSearchContext(ctx, []float32{1}, 5); err != nil {
+		t.Fatalf("VectorSearchContext failed: %v", err)
+	}
+	if err := client.Do(ctx, "POST", "/custom", map[string]int{"n": 1}, nil); err != nil {
+		t.Fatalf("Do failed: %v", err)
+	}
+
+	for _, key := range []string{"POST /nodes", "GET /nodes/1", "POST /query/hybrid", "POST /query/vector", "POST /custom"} {
+		if seen[key] != "beta" {
+			t.Errorf("Expected per-call header on %s, got %q", key, seen[key])
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package barqgraphdb
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// sendHedged sends a bodiless, idempotent request and, if it has not
+// completed within the hedge delay, races a second identical request against
+// it. The first successful response wins and the other attempt is cancelled.
+func (c *Client) sendHedged(ctx context.Context, method, endpoint string, header http.Header) (*response, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type attempt struct {
+		resp *response
+		err  error
+	}
+	results := make(chan attempt, 2)
+	launch := func() {
+		go func() {
+			resp, err := c.send(ctx, method, endpoint, nil, header)
+			results <- attempt{resp, err}
+		}()
+	}
+
+	launch()
+	timer := time.NewTimer(c.hedgeDelay)
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	for {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				launch()
+			}
+		case a := <-results:
+			pending--
+			if a.err == nil || pending == 0 {
+				return a.resp, a.err
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package barqgraphdb
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgedRequestWins(t *testing.T) {
+	var calls int32
+	slowCancelled := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The primary attempt stalls until the client gives up on it.
+			select {
+			case <-r.Context().Done():
+				close(slowCancelled)
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte(`{"status":"healthy","version":"hedge"}`))
+	}, WithHedging(20*time.Millisecond))
+
+	start := time.Now()
+	health, err := client.Health()
+	if err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if health.Version != "hedge" {
+		t.Errorf("Expected hedged response, got %+v", health)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected hedge to answer quickly, took %v", elapsed)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected 2 attempts, got %d", n)
+	}
+
+	select {
+	case <-slowCancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the slow attempt to be cancelled")
+	}
+}
+
+func TestHedgingSkipsFastAndNonGET(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Method == "POST" {
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.Write([]byte(`{"status":"healthy"}`))
+	}, WithHedging(10*time.Millisecond))
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("Expected no hedged attempts, got %d requests", n)
+	}
+}
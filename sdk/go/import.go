@@ -0,0 +1,256 @@
+package barqgraphdb
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// importBatchSize is the number of nodes created per request while
+// importing.
+const importBatchSize = 500
+
+// RowError describes a malformed row of an imported file.
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportNodesCSV reads nodes from CSV and creates them in batches, returning
+// how many were created. The first row must be the header id,label with an
+// optional third column rule_tags holding semicolon-separated tags.
+//
+// Malformed rows are skipped and reported as *RowError values joined into
+// the returned error, while the remaining rows are still imported. A failed
+// batch request stops the import.
+func (c *Client) ImportNodesCSV(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	if !validNodeCSVHeader(header) {
+		return 0, fmt.Errorf("%w: CSV header must be id,label[,rule_tags], got %s", ErrInvalidArgument, strings.Join(header, ","))
+	}
+
+	imported := 0
+	var rowErrs []error
+	batch := make([]Node, 0, importBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := c.CreateNodes(batch); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return imported, errors.Join(append(rowErrs, err)...)
+			}
+			rowErrs = append(rowErrs, &RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		node, err := parseNodeCSVRecord(record, len(header))
+		if err != nil {
+			rowErrs = append(rowErrs, &RowError{Line: line, Err: err})
+			continue
+		}
+		batch = append(batch, node)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return imported, errors.Join(append(rowErrs, err)...)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		rowErrs = append(rowErrs, err)
+	}
+	return imported, errors.Join(rowErrs...)
+}
+
+func validNodeCSVHeader(header []string) bool {
+	want := []string{"id", "label", "rule_tags"}
+	if len(header) < 2 || len(header) > len(want) {
+		return false
+	}
+	for i, name := range header {
+		if !strings.EqualFold(strings.TrimSpace(name), want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNodeCSVRecord(record []string, columns int) (Node, error) {
+	if len(record) != columns {
+		return Node{}, fmt.Errorf("expected %d fields, got %d", columns, len(record))
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+	if err != nil {
+		return Node{}, fmt.Errorf("invalid id %q", record[0])
+	}
+	node := Node{ID: id, Label: record[1]}
+	if columns == 3 {
+		for _, tag := range strings.Split(record[2], ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				node.RuleTags = append(node.RuleTags, tag)
+			}
+		}
+	}
+	return node, nil
+}
+
+// GraphDocument is a whole graph as accepted by ImportGraph.
+type GraphDocument struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// ImportGraph creates every node and edge of doc in a single request. The
+// whole request is encoded in memory first; use ImportGraphStream for
+// graphs too large for that.
+func (c *Client) ImportGraph(doc *GraphDocument) error {
+	if c.labelPrefix != "" {
+		prefixed := GraphDocument{Nodes: make([]Node, len(doc.Nodes)), Edges: doc.Edges}
+		for i := range doc.Nodes {
+			prefixed.Nodes[i] = *c.prefixLabel(&doc.Nodes[i])
+		}
+		doc = &prefixed
+	}
+	return c.doMutation("POST", "/import", doc, nil)
+}
+
+// ImportGraphStream sends the same single import request as ImportGraph,
+// but encodes the nodes and edges into the request body as the iterators
+// yield them, through an io.Pipe, so memory use stays flat however large the
+// graph is. Either iterator may be nil.
+//
+// The request is bounded by ctx rather than the client's timeout, since a
+// large import may legitimately take a long time, and it is never retried
+// because the body cannot be replayed.
+func (c *Client) ImportGraphStream(ctx context.Context, nodes iter.Seq[Node], edges iter.Seq[Edge]) error {
+	endpoint := "/import"
+	if c.dryRun {
+		endpoint = withQueryParam(endpoint, "dry_run", "true")
+	}
+	header := http.Header{}
+	if c.requestIDGenerator != nil {
+		header.Set("X-Request-ID", c.requestIDGenerator())
+	}
+
+	if nodes != nil && c.labelPrefix != "" {
+		unprefixed := nodes
+		nodes = func(yield func(Node) bool) {
+			for node := range unprefixed {
+				if !yield(*c.prefixLabel(&node)) {
+					return
+				}
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	go func() {
+		pw.CloseWithError(c.writeGraphDocument(pw, nodes, edges))
+	}()
+
+	resp, err := c.roundTripReader(ctx, "POST", endpoint, pr, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		err := parseError(resp.StatusCode, respBody)
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			apiErr.Method = "POST"
+			apiErr.Endpoint = endpoint
+			apiErr.RequestID = header.Get("X-Request-ID")
+		}
+		return err
+	}
+
+	if c.dryRun {
+		var report DryRunReport
+		if err := c.decodeBody(respBody, &report); err != nil {
+			return err
+		}
+		c.dryRunMu.Lock()
+		c.dryRunReport.merge(report)
+		c.dryRunMu.Unlock()
+	}
+	return nil
+}
+
+// writeGraphDocument writes a GraphDocument to w one element at a time,
+// encoding each like any other request body.
+func (c *Client) writeGraphDocument(w io.Writer, nodes iter.Seq[Node], edges iter.Seq[Edge]) error {
+	bw := bufio.NewWriterSize(w, 64<<10)
+	bw.WriteString(`{"nodes":`)
+	if err := writeJSONArray(c, bw, nodes); err != nil {
+		return err
+	}
+	bw.WriteString(`,"edges":`)
+	if err := writeJSONArray(c, bw, edges); err != nil {
+		return err
+	}
+	bw.WriteByte('}')
+	return bw.Flush()
+}
+
+// writeJSONArray writes the values of seq to bw as a JSON array. Write
+// errors are sticky in a bufio.Writer and surface at the caller's Flush.
+func writeJSONArray[T any](c *Client, bw *bufio.Writer, seq iter.Seq[T]) error {
+	bw.WriteByte('[')
+	if seq != nil {
+		first := true
+		for v := range seq {
+			data, err := c.encodeBody(&v)
+			if err != nil {
+				return err
+			}
+			if !first {
+				bw.WriteByte(',')
+			}
+			first = false
+			if _, err := bw.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.WriteByte(']')
+}
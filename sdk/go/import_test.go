@@ -0,0 +1,228 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestImportNodesCSV(t *testing.T) {
+	rec := &batchRecorder{}
+	client := newTestClient(t, rec.handler(t))
+	input := "id,label,rule_tags\n" +
+		"1,User,admin;staff\n" +
+		"x,Broken,\n" +
+		"2,\"Doc, draft\",\n" +
+		"3,Short\n" +
+		"4,Team,ops\n"
+
+	imported, err := client.ImportNodesCSV(strings.NewReader(input))
+	if imported != 3 {
+		t.Errorf("Expected 3 nodes imported, got %d", imported)
+	}
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Line != 3 {
+		t.Fatalf("Expected a row error for line 3, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("Expected errors for lines 3 and 5, got %v", err)
+	}
+
+	nodes := rec.nodes()
+	if len(nodes) != 3 {
+		t.Fatalf("Expected 3 nodes sent, got %+v", nodes)
+	}
+	if nodes[0].ID != 1 || len(nodes[0].RuleTags) != 2 || nodes[0].RuleTags[1] != "staff" {
+		t.Errorf("unexpected first node %+v", nodes[0])
+	}
+	if nodes[1].Label != "Doc, draft" || nodes[1].RuleTags != nil {
+		t.Errorf("unexpected second node %+v", nodes[1])
+	}
+}
+
+func TestImportNodesCSVBatches(t *testing.T) {
+	rec := &batchRecorder{}
+	client := newTestClient(t, rec.handler(t))
+	var b strings.Builder
+	b.WriteString("id,label\n")
+	for i := 1; i <= importBatchSize+1; i++ {
+		fmt.Fprintf(&b, "%d,n\n", i)
+	}
+
+	imported, err := client.ImportNodesCSV(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ImportNodesCSV failed: %v", err)
+	}
+	if imported != importBatchSize+1 || len(rec.nodes()) != importBatchSize+1 {
+		t.Errorf("Expected %d nodes imported, got %d", importBatchSize+1, imported)
+	}
+}
+
+func TestImportNodesCSVBadHeader(t *testing.T) {
+	client := newTestClient(t, (&batchRecorder{}).handler(t))
+	if _, err := client.ImportNodesCSV(strings.NewReader("name,label\n1,a\n")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument for a bad header, got %v", err)
+	}
+}
+
+func TestImportGraph(t *testing.T) {
+	var doc GraphDocument
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/import" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	err := client.ImportGraph(&GraphDocument{
+		Nodes: []Node{{ID: 1, Label: "a"}, {ID: 2, Label: "b"}},
+		Edges: []Edge{{From: 1, To: 2, EdgeType: "LINKS"}},
+	})
+	if err != nil {
+		t.Fatalf("ImportGraph failed: %v", err)
+	}
+	if len(doc.Nodes) != 2 || len(doc.Edges) != 1 || doc.Edges[0].EdgeType != "LINKS" {
+		t.Errorf("unexpected document %+v", doc)
+	}
+}
+
+func TestImportGraphStreamFlatMemory(t *testing.T) {
+	const n = 5000
+	var received, nodes, edges int64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/import" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		counted := &countingReader{r: r.Body}
+		dec := json.NewDecoder(counted)
+		depth, section := 0, ""
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Errorf("invalid JSON body: %v", err)
+				return
+			}
+			switch tok {
+			case json.Delim('{'), json.Delim('['):
+				depth++
+				if depth == 3 && tok == json.Delim('{') {
+					if section == "nodes" {
+						nodes++
+					} else {
+						edges++
+					}
+				}
+			case json.Delim('}'), json.Delim(']'):
+				depth--
+			default:
+				if s, ok := tok.(string); ok && depth == 1 {
+					section = s
+				}
+			}
+		}
+		atomic.StoreInt64(&received, counted.n)
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	embedding := make([]float32, 512)
+	for i := range embedding {
+		embedding[i] = float32(i) / 3
+	}
+	nodeSeq := func(yield func(Node) bool) {
+		for i := uint64(1); i <= n; i++ {
+			if !yield(Node{ID: i, Label: "synthetic", Embedding: embedding}) {
+				return
+			}
+		}
+	}
+	edgeSeq := func(yield func(Edge) bool) {
+		for i := uint64(1); i < n; i++ {
+			if !yield(Edge{From: i, To: i + 1, EdgeType: "NEXT"}) {
+				return
+			}
+		}
+	}
+
+	runtime.GC()
+	var base runtime.MemStats
+	runtime.ReadMemStats(&base)
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var m runtime.MemStats
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+			}
+			runtime.ReadMemStats(&m)
+			if m.HeapAlloc > peak {
+				peak = m.HeapAlloc
+			}
+		}
+	}()
+
+	err := client.ImportGraphStream(context.Background(), nodeSeq, edgeSeq)
+	close(done)
+	<-sampled
+	if err != nil {
+		t.Fatalf("ImportGraphStream failed: %v", err)
+	}
+	if nodes != n || edges != n-1 {
+		t.Errorf("Expected %d nodes and %d edges, got %d and %d", n, n-1, nodes, edges)
+	}
+
+	size := uint64(atomic.LoadInt64(&received))
+	var growth uint64
+	if peak > base.HeapAlloc {
+		growth = peak - base.HeapAlloc
+	}
+	if growth > size/4 {
+		t.Errorf("Expected flat memory, heap grew by %d bytes for a %d byte body", growth, size)
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestImportGraphStreamError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "edge references missing node"})
+	})
+
+	edges := func(yield func(Edge) bool) {
+		yield(Edge{From: 1, To: 99, EdgeType: "LINKS"})
+	}
+	err := client.ImportGraphStream(context.Background(), nil, edges)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Endpoint != "/import" {
+		t.Errorf("Expected a 400 *Error for /import, got %v", err)
+	}
+}
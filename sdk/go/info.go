@@ -0,0 +1,95 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Feature flags a server may advertise in ServerInfo.Features.
+const (
+	FeatureWeightedEdges = "weighted_edges"
+	FeatureStreaming     = "streaming"
+	FeaturePageRank      = "pagerank"
+)
+
+// ServerInfo describes the server's version and capabilities.
+type ServerInfo struct {
+	Version         string       `json:"version"`
+	Features        []string     `json:"features"`
+	MaxEmbeddingDim int          `json:"max_embedding_dim"`
+	Limits          ServerLimits `json:"limits"`
+}
+
+// ServerLimits holds the request limits enforced by the server. A zero value
+// means the server did not report that limit.
+type ServerLimits struct {
+	MaxBatchSize    int   `json:"max_batch_size"`
+	MaxHops         int   `json:"max_hops"`
+	MaxK            int   `json:"max_k"`
+	MaxRequestBytes int64 `json:"max_request_bytes"`
+}
+
+// HasFeature reports whether the server advertises the given feature.
+func (i *ServerInfo) HasFeature(feature string) bool {
+	for _, f := range i.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerInfo returns the server's version, supported features and limits.
+// The result also refreshes the capability cache used to gate optional
+// methods.
+func (c *Client) ServerInfo() (*ServerInfo, error) {
+	var result ServerInfo
+	err := c.doRequest("GET", "/info", nil, &result)
+	if err == nil {
+		c.capsMu.Lock()
+		c.caps, c.capsKnown = &result, true
+		c.capsMu.Unlock()
+	}
+	return &result, err
+}
+
+// requireFeature fails fast with ErrUnsupportedFeature if the server does not
+// advertise feature. Capabilities are fetched lazily on first use and cached.
+// Servers that predate the /info endpoint are assumed to support everything,
+// leaving the method itself to report any problem.
+func (c *Client) requireFeature(feature string) error {
+	caps, err := c.capabilities()
+	if err != nil {
+		return err
+	}
+	if caps != nil && !caps.HasFeature(feature) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFeature, feature)
+	}
+	return nil
+}
+
+// capabilities returns the cached ServerInfo, fetching it on first use. It
+// returns nil without error for servers that predate the /info endpoint.
+func (c *Client) capabilities() (*ServerInfo, error) {
+	c.capsMu.Lock()
+	caps, known := c.caps, c.capsKnown
+	c.capsMu.Unlock()
+	if known {
+		return caps, nil
+	}
+
+	info, err := c.ServerInfo()
+	var apiErr *Error
+	switch {
+	case err == nil:
+		return info, nil
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		c.capsMu.Lock()
+		c.caps, c.capsKnown = nil, true
+		c.capsMu.Unlock()
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
@@ -0,0 +1,91 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestServerInfo(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/info" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"version": "0.9.0",
+			"features": ["weighted_edges", "pagerank"],
+			"max_embedding_dim": 4096,
+			"limits": {"max_batch_size": 1000, "max_hops": 6, "max_k": 500, "max_request_bytes": 10485760}
+		}`))
+	})
+
+	info, err := client.ServerInfo()
+	if err != nil {
+		t.Fatalf("ServerInfo failed: %v", err)
+	}
+	if info.Version != "0.9.0" || info.MaxEmbeddingDim != 4096 {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if info.Limits.MaxBatchSize != 1000 || info.Limits.MaxHops != 6 || info.Limits.MaxK != 500 || info.Limits.MaxRequestBytes != 10485760 {
+		t.Errorf("unexpected limits %+v", info.Limits)
+	}
+	if !info.HasFeature(FeatureWeightedEdges) || !info.HasFeature(FeaturePageRank) {
+		t.Errorf("Expected weighted_edges and pagerank features, got %v", info.Features)
+	}
+	if info.HasFeature(FeatureStreaming) {
+		t.Error("Expected streaming to be unsupported")
+	}
+}
+
+func TestFeatureGateRejectsUnsupported(t *testing.T) {
+	infoCalls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/info" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			return
+		}
+		infoCalls++
+		w.Write([]byte(`{"version":"0.9.0","features":["pagerank"]}`))
+	})
+
+	if _, _, err := client.ShortestPathWeighted(1, 2); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Errorf("Expected ErrUnsupportedFeature, got %v", err)
+	}
+	if err := client.AddWeightedEdge(1, 2, "ROAD", 2.5); !errors.Is(err, ErrUnsupportedFeature) {
+		t.Errorf("Expected ErrUnsupportedFeature, got %v", err)
+	}
+	if infoCalls != 1 {
+		t.Errorf("Expected capabilities to be fetched once, got %d calls", infoCalls)
+	}
+}
+
+func TestFeatureGateAllowsSupported(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["weighted_edges"]}`))
+		case "/edges":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	if err := client.AddWeightedEdge(1, 2, "ROAD", 2.5); err != nil {
+		t.Errorf("AddWeightedEdge failed: %v", err)
+	}
+}
+
+func TestFeatureGateWithoutInfoEndpoint(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.AddWeightedEdge(1, 2, "ROAD", 2.5); err != nil {
+		t.Errorf("Expected servers without /info to be ungated, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package barqgraphdb
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// AllNodes returns an iterator over every node, fetching pageSize nodes at a
+// time as the loop advances. A failed page fetch is yielded as an error and
+// ends the iteration. Breaking out of the loop stops further requests. A
+// server that ignores the page size and returns more nodes is taken to have
+// returned them all; one that ignores the offset and repeats a page ends the
+// iteration with an error.
+func (c *Client) AllNodes(pageSize int, opts ...ListOption) iter.Seq2[Node, error] {
+	return func(yield func(Node, error) bool) {
+		if pageSize <= 0 {
+			yield(Node{}, fmt.Errorf("%w: page size must be positive, got %d", ErrInvalidArgument, pageSize))
+			return
+		}
+		var prev []Node
+		for offset := 0; ; offset += pageSize {
+			page, err := c.ListNodesPaged(offset, pageSize, opts...)
+			if err == nil {
+				err = checkPage(prev, page, offset)
+			}
+			if err != nil {
+				yield(Node{}, err)
+				return
+			}
+			for _, node := range page {
+				if !yield(node, nil) {
+					return
+				}
+			}
+			if len(page) != pageSize {
+				return
+			}
+			prev = page
+		}
+	}
+}
+
+// checkPage reports an error when page, fetched at offset, repeats the
+// previous page, meaning the server ignored the offset and paging would
+// never advance.
+func checkPage[T any](prev, page []T, offset int) error {
+	if len(page) > 0 && reflect.DeepEqual(prev, page) {
+		return fmt.Errorf("barqgraphdb: server returned the same page again at offset %d; it does not support paging", offset)
+	}
+	return nil
+}
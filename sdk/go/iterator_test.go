@@ -0,0 +1,130 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// nodesServer serves n nodes with offset/limit paging and counts requests.
+func nodesServer(t *testing.T, n int, requests *int32) *Client {
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/nodes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		atomic.AddInt32(requests, 1)
+		q := r.URL.Query()
+		offset, _ := strconv.Atoi(q.Get("offset"))
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		nodes := []Node{}
+		for i := offset; i < n && i < offset+limit; i++ {
+			nodes = append(nodes, Node{ID: uint64(i + 1), Label: "n"})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": nodes})
+	})
+}
+
+func TestAllNodes(t *testing.T) {
+	var requests int32
+	client := nodesServer(t, 7, &requests)
+
+	var ids []uint64
+	for node, err := range client.AllNodes(3) {
+		if err != nil {
+			t.Fatalf("AllNodes failed: %v", err)
+		}
+		ids = append(ids, node.ID)
+	}
+	if len(ids) != 7 || ids[0] != 1 || ids[6] != 7 {
+		t.Errorf("Expected nodes 1..7, got %v", ids)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestAllNodesStopEarly(t *testing.T) {
+	var requests int32
+	client := nodesServer(t, 7, &requests)
+
+	count := 0
+	for _, err := range client.AllNodes(3) {
+		if err != nil {
+			t.Fatalf("AllNodes failed: %v", err)
+		}
+		count++
+		if count == 4 {
+			break
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 page requests, got %d", requests)
+	}
+}
+
+func TestAllNodesError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "boom"})
+	})
+
+	var errs []error
+	for _, err := range client.AllNodes(3) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil {
+		t.Fatalf("Expected a single error, got %v", errs)
+	}
+
+	for _, err := range client.AllNodes(0) {
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Expected ErrInvalidArgument, got %v", err)
+		}
+	}
+}
+
+func TestAllNodesUnpagedServer(t *testing.T) {
+	// serve ignores offset, and also limit when honorLimit is false, as the
+	// bundled server's list endpoint does.
+	serve := func(honorLimit bool, requests *int) *Client {
+		return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			*requests++
+			n := 7
+			if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); honorLimit && limit < n {
+				n = limit
+			}
+			nodes := []Node{}
+			for i := 0; i < n; i++ {
+				nodes = append(nodes, Node{ID: uint64(i + 1), Label: "n"})
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": nodes})
+		})
+	}
+
+	var requests int
+	var ids []uint64
+	for node, err := range serve(false, &requests).AllNodes(3) {
+		if err != nil {
+			t.Fatalf("AllNodes failed: %v", err)
+		}
+		ids = append(ids, node.ID)
+	}
+	if len(ids) != 7 || requests != 1 {
+		t.Errorf("Expected all 7 nodes from 1 request, got %v from %d", ids, requests)
+	}
+
+	requests = 0
+	var errs []error
+	count := 0
+	for _, err := range serve(true, &requests).AllNodes(3) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		count++
+	}
+	if len(errs) != 1 || count != 3 || requests != 2 {
+		t.Errorf("Expected 3 nodes then an error after 2 requests, got %d nodes, errors %v, %d requests", count, errs, requests)
+	}
+}
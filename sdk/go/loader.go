@@ -0,0 +1,220 @@
+package barqgraphdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults for BulkLoader batch sizing.
+const (
+	defaultMinBatchSize  = 10
+	defaultMaxBatchSize  = 1000
+	defaultTargetLatency = time.Second
+	defaultRetryBackoff  = 100 * time.Millisecond
+	bulkLoaderMaxRetries = 3
+)
+
+// BulkLoader creates large numbers of nodes in batches whose size adapts to
+// how the server copes: it doubles after a batch that finishes within the
+// target latency and halves after a slow or failed one, staying within the
+// configured bounds. A failed batch is retried at the smaller size after a
+// backoff. A BulkLoader is safe for concurrent use, though batches from concurrent
+// Load calls share one batch size.
+type BulkLoader struct {
+	client        *Client
+	minBatch      int
+	maxBatch      int
+	targetLatency time.Duration
+	retryBackoff  time.Duration
+
+	maxQueueDepth    int
+	backpressureWait time.Duration
+
+	mu        sync.Mutex
+	batchSize int
+}
+
+// BulkLoaderOption configures a BulkLoader.
+type BulkLoaderOption func(*BulkLoader)
+
+// BatchBounds sets the smallest and largest batch a BulkLoader may send.
+// The defaults are 10 and 1000. Invalid bounds are ignored.
+func BatchBounds(minSize, maxSize int) BulkLoaderOption {
+	return func(l *BulkLoader) {
+		if minSize > 0 && maxSize >= minSize {
+			l.minBatch, l.maxBatch = minSize, maxSize
+		}
+	}
+}
+
+// TargetLatency sets how long a batch may take before the BulkLoader stops
+// growing its batches and shrinks them instead. The default is one second.
+func TargetLatency(d time.Duration) BulkLoaderOption {
+	return func(l *BulkLoader) {
+		if d > 0 {
+			l.targetLatency = d
+		}
+	}
+}
+
+// RetryBackoff sets how long a BulkLoader waits before retrying a failed
+// batch, doubling the wait for each consecutive failure. The default is
+// 100ms.
+func RetryBackoff(d time.Duration) BulkLoaderOption {
+	return func(l *BulkLoader) {
+		if d > 0 {
+			l.retryBackoff = d
+		}
+	}
+}
+
+// Backpressure makes a BulkLoader check ServerLoad before each batch and,
+// while the server's queue is deeper than maxQueueDepth, halve its batch
+// size and wait before checking again. The wait starts at wait and doubles
+// up to 30 times that. If the load cannot be fetched, for example because
+// the server predates /admin/load, the batch is sent anyway.
+func Backpressure(maxQueueDepth int, wait time.Duration) BulkLoaderOption {
+	return func(l *BulkLoader) {
+		if maxQueueDepth >= 0 && wait > 0 {
+			l.maxQueueDepth, l.backpressureWait = maxQueueDepth, wait
+		}
+	}
+}
+
+// NewBulkLoader returns a BulkLoader that starts with batches of 100 nodes,
+// clamped to its bounds.
+func (c *Client) NewBulkLoader(opts ...BulkLoaderOption) *BulkLoader {
+	l := &BulkLoader{
+		client:        c,
+		minBatch:      defaultMinBatchSize,
+		maxBatch:      defaultMaxBatchSize,
+		targetLatency: defaultTargetLatency,
+		retryBackoff:  defaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.batchSize = min(max(bulkChunkSize, l.minBatch), l.maxBatch)
+	return l
+}
+
+// BatchSize returns the size the next batch will have.
+func (l *BulkLoader) BatchSize() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.batchSize
+}
+
+// Load creates nodes batch by batch. If it cannot finish, because ctx ends,
+// a batch fails with an error that retrying will not fix, or a batch keeps
+// failing, it returns a *PartialError saying how many nodes were created.
+func (l *BulkLoader) Load(ctx context.Context, nodes []Node) error {
+	failures := 0
+	for lo := 0; lo < len(nodes); {
+		if err := ctx.Err(); err != nil {
+			return &PartialError{Completed: lo, Total: len(nodes), Err: err}
+		}
+		if err := l.waitForCapacity(ctx); err != nil {
+			return &PartialError{Completed: lo, Total: len(nodes), Err: err}
+		}
+		hi := min(lo+l.BatchSize(), len(nodes))
+		batch := make([]*Node, hi-lo)
+		for i := range batch {
+			prepared, err := l.client.prepareNode(&nodes[lo+i])
+			if err != nil {
+				return &PartialError{Completed: lo, Total: len(nodes), Err: fmt.Errorf("node %d: %w", nodes[lo+i].ID, err)}
+			}
+			batch[i] = prepared
+		}
+		payload := struct {
+			Nodes []*Node `json:"nodes"`
+		}{
+			Nodes: batch,
+		}
+
+		start := time.Now()
+		err := l.client.doMutationContext(ctx, "POST", "/nodes/batch", payload, nil)
+		l.adapt(time.Since(start), err)
+		if err != nil {
+			failures++
+			if ctx.Err() != nil || !retryableBatchError(err) || failures > bulkLoaderMaxRetries {
+				return &PartialError{Completed: lo, Total: len(nodes), Err: err}
+			}
+			timer := time.NewTimer(l.retryBackoff << (failures - 1))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return &PartialError{Completed: lo, Total: len(nodes), Err: err}
+			}
+			continue
+		}
+		failures = 0
+		lo = hi
+	}
+	return nil
+}
+
+// adapt resizes batches after one took elapsed and finished with err.
+func (l *BulkLoader) adapt(elapsed time.Duration, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil || elapsed > l.targetLatency {
+		l.batchSize = max(l.batchSize/2, l.minBatch)
+	} else {
+		l.batchSize = min(l.batchSize*2, l.maxBatch)
+	}
+}
+
+// retryableBatchError reports whether a failed batch may succeed if sent
+// again, possibly smaller: network errors, timeouts, overload and server
+// errors, but not requests the server rejected as invalid.
+func retryableBatchError(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusRequestTimeout, http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return true
+	}
+	return apiErr.StatusCode >= 500
+}
+
+// waitForCapacity blocks while Backpressure is configured and the server
+// reports a queue deeper than allowed, shrinking batches meanwhile. It
+// returns early with ctx's error.
+func (l *BulkLoader) waitForCapacity(ctx context.Context) error {
+	if l.backpressureWait == 0 {
+		return nil
+	}
+	wait := l.backpressureWait
+	for {
+		load, err := l.client.serverLoad(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return nil
+		}
+		if load.QueueDepth <= l.maxQueueDepth {
+			return nil
+		}
+		l.mu.Lock()
+		l.batchSize = max(l.batchSize/2, l.minBatch)
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		wait = min(wait*2, 30*l.backpressureWait)
+	}
+}
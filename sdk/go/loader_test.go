@@ -0,0 +1,274 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loaderServer records the size of every node batch it receives. respond
+// decides each response from the 1-based request number.
+type loaderServer struct {
+	mu       sync.Mutex
+	attempts []int
+	created  int
+}
+
+func (s *loaderServer) handler(t *testing.T, respond func(n int, w http.ResponseWriter) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Nodes []Node `json:"nodes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.attempts = append(s.attempts, len(body.Nodes))
+		n := len(s.attempts)
+		s.mu.Unlock()
+		if respond != nil && !respond(n, w) {
+			return
+		}
+		s.mu.Lock()
+		s.created += len(body.Nodes)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func makeNodes(n int) []Node {
+	nodes := make([]Node, n)
+	for i := range nodes {
+		nodes[i] = Node{ID: uint64(i + 1), Label: "n"}
+	}
+	return nodes
+}
+
+func TestBulkLoaderGrowsOnFastResponses(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, nil))
+	loader := client.NewBulkLoader(BatchBounds(10, 400))
+
+	if err := loader.Load(context.Background(), makeNodes(1000)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []int{100, 200, 400, 300}
+	if len(srv.attempts) != len(want) {
+		t.Fatalf("Expected batches %v, got %v", want, srv.attempts)
+	}
+	for i := range want {
+		if srv.attempts[i] != want[i] {
+			t.Errorf("Expected batches %v, got %v", want, srv.attempts)
+			break
+		}
+	}
+	if got := loader.BatchSize(); got != 400 {
+		t.Errorf("Expected batch size capped at 400, got %d", got)
+	}
+}
+
+func TestBulkLoaderShrinksOnErrors(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		if n <= 2 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "overloaded"})
+			return false
+		}
+		return true
+	}))
+	loader := client.NewBulkLoader(BatchBounds(10, 1000))
+
+	if err := loader.Load(context.Background(), makeNodes(60)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(srv.attempts) < 3 || srv.attempts[0] != 60 || srv.attempts[1] != 50 || srv.attempts[2] != 25 {
+		t.Errorf("Expected retries at 50 then 25 nodes, got %v", srv.attempts)
+	}
+	if srv.created != 60 {
+		t.Errorf("Expected all 60 nodes created, got %d", srv.created)
+	}
+}
+
+func TestBulkLoaderShrinksOnSlowResponses(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		time.Sleep(30 * time.Millisecond)
+		return true
+	}))
+	loader := client.NewBulkLoader(BatchBounds(20, 1000), TargetLatency(10*time.Millisecond))
+
+	if err := loader.Load(context.Background(), makeNodes(175)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []int{100, 50, 25}
+	for i := range want {
+		if i >= len(srv.attempts) || srv.attempts[i] != want[i] {
+			t.Fatalf("Expected batches to start %v, got %v", want, srv.attempts)
+		}
+	}
+	if got := loader.BatchSize(); got != 20 {
+		t.Errorf("Expected batch size to bottom out at 20, got %d", got)
+	}
+}
+
+func TestBulkLoaderTimeout(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		if n == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return true
+	}))
+	client.timeout = 50 * time.Millisecond
+	loader := client.NewBulkLoader()
+
+	if err := loader.Load(context.Background(), makeNodes(100)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(srv.attempts) != 3 || srv.attempts[1] != 50 {
+		t.Errorf("Expected the timed-out batch to be retried as two of 50, got %v", srv.attempts)
+	}
+}
+
+func TestBulkLoaderNonRetryableError(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		if n == 2 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad node"})
+			return false
+		}
+		return true
+	}))
+	loader := client.NewBulkLoader(BatchBounds(10, 10))
+
+	err := loader.Load(context.Background(), makeNodes(30))
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("Expected *PartialError, got %v", err)
+	}
+	if partial.Completed != 10 || partial.Total != 30 {
+		t.Errorf("Expected 10 of 30 completed, got %d of %d", partial.Completed, partial.Total)
+	}
+	if len(srv.attempts) != 2 {
+		t.Errorf("Expected no retry of a rejected batch, got %v", srv.attempts)
+	}
+}
+
+func TestBulkLoaderBackpressure(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	loadPolls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/admin/load":
+			loadPolls++
+			depth := 0
+			if loadPolls <= 2 {
+				depth = 50
+			}
+			events = append(events, "load")
+			writeJSON(w, http.StatusOK, LoadInfo{QueueDepth: depth})
+		case "/nodes/batch":
+			var body struct {
+				Nodes []Node `json:"nodes"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			events = append(events, "batch:"+strconv.Itoa(len(body.Nodes)))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	loader := client.NewBulkLoader(Backpressure(10, 5*time.Millisecond))
+
+	if err := loader.Load(context.Background(), makeNodes(30)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := []string{"load", "load", "load", "batch:25", "load", "batch:5"}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, events)
+	}
+}
+
+func TestBulkLoaderBackpressureWithoutLoadEndpoint(t *testing.T) {
+	srv := &loaderServer{}
+	batches := srv.handler(t, nil)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin/load" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+			return
+		}
+		batches(w, r)
+	})
+	loader := client.NewBulkLoader(Backpressure(10, time.Millisecond))
+
+	if err := loader.Load(context.Background(), makeNodes(30)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if srv.created != 30 {
+		t.Errorf("Expected all 30 nodes created, got %d", srv.created)
+	}
+}
+
+func TestBulkLoaderRetryBackoff(t *testing.T) {
+	var mu sync.Mutex
+	var times []time.Time
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		if n <= 2 {
+			writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "slow down"})
+			return false
+		}
+		return true
+	}))
+	loader := client.NewBulkLoader(RetryBackoff(20 * time.Millisecond))
+
+	if err := loader.Load(context.Background(), makeNodes(10)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(times) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < 20*time.Millisecond {
+		t.Errorf("Expected at least 20ms before the first retry, got %v", gap)
+	}
+	if gap := times[2].Sub(times[1]); gap < 40*time.Millisecond {
+		t.Errorf("Expected at least 40ms before the second retry, got %v", gap)
+	}
+}
+
+func TestBulkLoaderRetryBackoffCancelled(t *testing.T) {
+	srv := &loaderServer{}
+	client := newTestClient(t, srv.handler(t, func(n int, w http.ResponseWriter) bool {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "overloaded"})
+		return false
+	}))
+	loader := client.NewBulkLoader(RetryBackoff(time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := loader.Load(ctx, makeNodes(10))
+	var partial *PartialError
+	if !errors.As(err, &partial) || partial.Completed != 0 {
+		t.Fatalf("Expected *PartialError with nothing completed, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected Load to stop when ctx ended, took %v", elapsed)
+	}
+	if len(srv.attempts) != 1 {
+		t.Errorf("Expected no retry after ctx ended, got %v", srv.attempts)
+	}
+}
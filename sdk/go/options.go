@@ -0,0 +1,387 @@
+package barqgraphdb
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures optional Client behaviour.
+type Option func(*Client)
+
+// WithDryRun puts the client in dry-run mode. Mutating operations such as
+// CreateNode, AddEdge and SetEmbedding are sent with dry_run=true so the
+// server validates them without persisting anything. The server's findings
+// are accumulated and available from Client.DryRunReport.
+func WithDryRun() Option {
+	return func(c *Client) {
+		c.dryRun = true
+	}
+}
+
+// WithMaxIdleConnsPerHost sets how many idle keep-alive connections the
+// client keeps open to the server. The default is 2 (Go's
+// http.DefaultMaxIdleConnsPerHost), which forces high-throughput callers to
+// re-dial frequently.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Client) {
+		c.transport().MaxIdleConnsPerHost = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle keep-alive connection is kept
+// before being closed. The default is 90 seconds.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.transport().IdleConnTimeout = d
+	}
+}
+
+// transport returns the client's own *http.Transport, cloning Go's default
+// transport on first use so tuning never affects other HTTP clients.
+func (c *Client) transport() *http.Transport {
+	if t, ok := c.httpClient.Transport.(*http.Transport); ok {
+		return t
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	c.httpClient.Transport = t
+	return t
+}
+
+// WithHTTP2 controls HTTP/2. When enabled, concurrent requests are
+// multiplexed over a single connection where the server allows it. For https
+// base URLs HTTP/2 is negotiated over TLS, falling back to HTTP/1.1 if the
+// server does not offer it; for http base URLs h2c (HTTP/2 with prior
+// knowledge) is used, so the server must speak HTTP/2. When disabled, only
+// HTTP/1.1 is used. By default Go's standard behaviour applies: HTTP/2 over
+// TLS when offered, HTTP/1.1 otherwise.
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		t := c.transport()
+		var protocols http.Protocols
+		switch {
+		case !enabled:
+			protocols.SetHTTP1(true)
+		case strings.HasPrefix(strings.ToLower(c.baseURL), "https:"):
+			protocols.SetHTTP1(true)
+			protocols.SetHTTP2(true)
+		default:
+			protocols.SetUnencryptedHTTP2(true)
+		}
+		t.Protocols = &protocols
+		t.ForceAttemptHTTP2 = enabled
+	}
+}
+
+// WithHedging enables hedged reads. A GET that has not responded within
+// delay triggers a second, parallel attempt; whichever answers first is used
+// and the other is cancelled. This trims tail latency against replicated
+// clusters at the cost of extra load. Non-GET requests are never hedged.
+func WithHedging(delay time.Duration) Option {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+	}
+}
+
+// WithCache enables an in-memory cache for reads of individual nodes and
+// embeddings (GetNode, GetEmbedding). Cached responses are reused for ttl.
+// Writes made through this client (UpdateNode, SetEmbedding) invalidate the
+// affected node; use Client.InvalidateNode after out-of-band changes.
+func WithCache(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cache = newResponseCache(ttl)
+	}
+}
+
+// WithDebugBodies writes every request and response body, pretty-printed, to
+// w for troubleshooting. Bodies include full embeddings and node data, so
+// this should only be enabled deliberately and never in production logs.
+func WithDebugBodies(w io.Writer) Option {
+	return func(c *Client) {
+		c.debugWriter = w
+	}
+}
+
+// WithUniqueLabels treats node labels as unique keys. CreateNode looks up
+// the label first and returns ErrConflict instead of creating a second node
+// with it. The check is client-side and not atomic, so concurrent creators
+// can still race.
+func WithUniqueLabels() Option {
+	return func(c *Client) {
+		c.uniqueLabels = true
+	}
+}
+
+// WithDefaultEmbedding sets an embedding that CreateNode and CreateNodes
+// attach to nodes created without one, such as a zero vector of the index's
+// dimension, so every node stays present in the vector index.
+func WithDefaultEmbedding(embedding []float32) Option {
+	return func(c *Client) {
+		c.defaultEmbedding = append([]float32(nil), embedding...)
+	}
+}
+
+// WithRetry retries failed requests up to maxRetries times, waiting backoff
+// before the first retry and doubling it for each one after. Only network
+// errors and 502, 503 and 504 responses are retried, and only for requests
+// that are safe to repeat: GET, HEAD and OPTIONS always, other methods only
+// when WithIdempotencyKeys is also set.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryBackoff = backoff
+	}
+}
+
+// WithIdempotencyKeys sends a unique Idempotency-Key header with every
+// non-GET request, reused across its retries, so a server that deduplicates
+// by key can safely apply it once. This makes such requests retryable under
+// WithRetry.
+func WithIdempotencyKeys() Option {
+	return func(c *Client) {
+		c.idempotencyKeys = true
+	}
+}
+
+// WithEmbeddingChunkSize makes SetEmbedding upload embeddings longer than n
+// values as a series of chunked requests that the server reassembles. This
+// keeps very high-dimensional embeddings under gateway request size limits.
+func WithEmbeddingChunkSize(n int) Option {
+	return func(c *Client) {
+		c.embeddingChunkSize = n
+	}
+}
+
+// WithFieldMap adapts the client to deployments whose JSON schema names
+// fields differently, such as "node_id" instead of "id". fields maps the
+// SDK's field name to the server's; it is applied to every object in request
+// bodies, and reversed for responses. Query string parameters, and the keys
+// of data-keyed maps such as the label counts from ListLabels, are not
+// affected.
+func WithFieldMap(fields map[string]string) Option {
+	return func(c *Client) {
+		c.fieldMap = make(map[string]string, len(fields))
+		c.reverseFieldMap = make(map[string]string, len(fields))
+		for sdkName, serverName := range fields {
+			c.fieldMap[sdkName] = serverName
+			c.reverseFieldMap[serverName] = sdkName
+		}
+	}
+}
+
+// WithEndpointTimeouts sets per-endpoint request timeouts, keyed by path
+// without query string (for example "/query/hybrid" or "/health"), so slow
+// queries and quick probes can each have a fitting limit. Endpoints not in
+// the map use the client's default timeout.
+func WithEndpointTimeouts(timeouts map[string]time.Duration) Option {
+	return func(c *Client) {
+		c.endpointTimeouts = make(map[string]time.Duration, len(timeouts))
+		for path, timeout := range timeouts {
+			c.endpointTimeouts[path] = timeout
+		}
+	}
+}
+
+// WithRequestIDGenerator sends an X-Request-ID header produced by generate
+// with every request, so client and server logs can be correlated. Retries
+// of a request reuse its ID. The ID of a failed request is available as
+// Error.RequestID; if the server echoes a different ID, that one is used.
+// generate must be safe for concurrent use.
+func WithRequestIDGenerator(generate func() string) Option {
+	return func(c *Client) {
+		c.requestIDGenerator = generate
+	}
+}
+
+// WithStreamConnectTimeout bounds how long a streaming call waits for the
+// server to start responding. Once the stream is open no overall timeout
+// applies; use the call's context to end it. Defaults to the endpoint's
+// request timeout.
+func WithStreamConnectTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.streamConnectTimeout = timeout
+	}
+}
+
+// WithBase64Embeddings sends embeddings as base64 of their little-endian
+// float32 bytes, in an "embedding_b64" (or "query_embedding_b64") field,
+// instead of as a JSON number array. This roughly halves their size on the
+// wire. Base64 embeddings in responses are decoded transparently.
+func WithBase64Embeddings() Option {
+	return func(c *Client) {
+		c.base64Embeddings = true
+	}
+}
+
+// WithOnDuplicate sets how CreateNode handles a node ID that already exists:
+// return the conflict error (DuplicateError, the default), do nothing
+// (DuplicateIgnore), or replace the node (DuplicateOverwrite).
+func WithOnDuplicate(policy DuplicatePolicy) Option {
+	return func(c *Client) {
+		c.onDuplicate = policy
+	}
+}
+
+// WithEmbeddingFit sets how CreateNode and SetEmbedding handle embeddings
+// whose length differs from dim, the dimension of the server's vector index:
+// reject them with ErrInvalidArgument (FitError, the default), zero-pad
+// short ones (FitPad), or cut long ones down (FitTruncate). This eases mixing models
+// with different output sizes. dim must be the index's actual dimension,
+// not ServerInfo.MaxEmbeddingDim, which is only an upper bound. The fit also
+// applies to CreateNodes, CreateNodeAutoID, CreateNodeWithEdges,
+// UpdateEmbedding and BulkLoader; other writes, such as UpdateNode and
+// ImportGraph, send embeddings as given.
+func WithEmbeddingFit(mode EmbeddingFit, dim int) Option {
+	return func(c *Client) {
+		c.embeddingFit = mode
+		c.embeddingFitDim = dim
+	}
+}
+
+// WithEmbeddingPrecision sets the precision of embeddings on the wire. With
+// 16, embeddings are sent and received as base64 half-precision floats in
+// "embedding_f16" (or "query_embedding_f16") fields, half the size of
+// WithBase64Embeddings; the server must support half precision. Any other
+// value keeps full 32-bit precision.
+//
+// Half precision keeps about three significant decimal digits: each value
+// moves by up to 0.05% of its magnitude, magnitudes below 6e-5 lose further
+// precision, and values beyond ±65504 overflow. For normalized embeddings
+// this typically shifts similarity scores in the third or fourth decimal
+// place, which can reorder near-tied results.
+func WithEmbeddingPrecision(bits int) Option {
+	return func(c *Client) {
+		c.embeddingPrecision = bits
+	}
+}
+
+// WithServerTime makes client-generated timestamps, in CreateNodeNow and
+// RecordDecision, follow the server's clock rather than the local one, so
+// clients with skewed clocks still order events consistently. The offset is
+// measured once from GET /time, or from the Date header of its response.
+func WithServerTime() Option {
+	return func(c *Client) {
+		c.serverTime = true
+	}
+}
+
+// WithDecisionNotesTemplate fills in the notes of decisions recorded without
+// any, so agents log decisions in a consistent format. template receives the
+// decision about to be recorded, including any CreatedAt set by
+// WithServerTime. Decisions that already have notes are sent unchanged.
+func WithDecisionNotesTemplate(template func(d *Decision) string) Option {
+	return func(c *Client) {
+		c.decisionNotesTemplate = template
+	}
+}
+
+// WithAutoReconnect makes Subscribe reopen a dropped event stream, backing
+// off exponentially between attempts, and resume from the last event
+// received via the Last-Event-ID header.
+func WithAutoReconnect() Option {
+	return func(c *Client) {
+		c.autoReconnect = true
+		c.reconnectBackoff = defaultReconnectBackoff
+	}
+}
+
+// WithTenant scopes the client to a tenant of a multi-tenant deployment:
+// every request, including streams and subscriptions, carries tenantID in
+// the X-Tenant-ID header.
+func WithTenant(tenantID string) Option {
+	return func(c *Client) {
+		c.tenantID = tenantID
+	}
+}
+
+// WithMaxHopsCeiling protects the server from runaway traversals by limiting
+// the maxHops of hybrid queries to n. Queries asking for more are clamped to
+// n (HopsClamp) or rejected with ErrInvalidArgument (HopsReject).
+func WithMaxHopsCeiling(n int, policy HopsPolicy) Option {
+	return func(c *Client) {
+		c.maxHopsCeiling = n
+		c.hopsPolicy = policy
+	}
+}
+
+// WithDistanceMetric selects the metric the server uses to compare
+// embeddings in VectorSearch and HybridQuery: MetricCosine, MetricL2 or
+// MetricDot, if the server supports several. By default the server's own
+// default applies. Client.CosineSimilarity warns when the metric is not
+// cosine.
+func WithDistanceMetric(metric string) Option {
+	return func(c *Client) {
+		c.distanceMetric = metric
+	}
+}
+
+// WithStrictDecoding makes responses containing fields the SDK does not know
+// about fail to decode, so schema drift between client and server surfaces
+// immediately during development. By default unknown fields are ignored.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strictDecoding = true
+	}
+}
+
+// WithRequestSigner sets a function that signs every outgoing request, for
+// API gateways that verify signatures. It is called with the fully prepared
+// request and its raw body (nil for requests without one, or whose body is
+// streamed like ImportGraphStream's) just before each attempt is sent, and
+// typically computes an HMAC over the method, path and body and sets it as a
+// header. An error from sign aborts the request.
+func WithRequestSigner(sign func(req *http.Request, body []byte) error) Option {
+	return func(c *Client) {
+		c.requestSigner = sign
+	}
+}
+
+// WithAutoTouch makes UpdateNode set the node's UpdatedAt to the current
+// time, taken from the server's clock with WithServerTime, so modification
+// times stay accurate without the caller maintaining them.
+func WithAutoTouch() Option {
+	return func(c *Client) {
+		c.autoTouch = true
+	}
+}
+
+// WithExternalKeyField names the node property or rule tag prefix that
+// GetNodeByExternalKey matches keys against, for example "external_id" or
+// "sku:". By default the server uses its own configured key field.
+func WithExternalKeyField(field string) Option {
+	return func(c *Client) {
+		c.externalKeyField = field
+	}
+}
+
+// WithLabelPrefix namespaces node labels, for several tenants or
+// applications sharing one server. Nodes are created, updated, upserted and
+// imported with prefix prepended to their label; FindNodeIDsByLabel,
+// SearchNodesByLabel, RenameLabel and DeleteNodesByLabel work on the
+// prefixed label; and SearchNodesByLabel, FindNodesByTags and ListLabels
+// return labels with the prefix stripped, leaving out other namespaces.
+// Other reads, such as GetNode, return labels as stored, prefix included; a
+// label that already carries the prefix is not prefixed again, so such a
+// node can be changed and passed back to UpdateNode or UpsertNode.
+func WithLabelPrefix(prefix string) Option {
+	return func(c *Client) {
+		c.labelPrefix = prefix
+	}
+}
+
+// WithResultDecoder registers decode to post-process the results of every
+// query sent to endpoint, such as "/query/hybrid" or "/query/vector", after
+// they are decoded. It is the place to enrich results, for example with a
+// client-computed similarity, or to filter them by a predicate. endpoint is
+// the request path without its query string; registering the same endpoint
+// again replaces the earlier hook. An error from decode is returned from the
+// query. Streamed results are not passed through the hook.
+func WithResultDecoder(endpoint string, decode func(results []HybridResult) ([]HybridResult, error)) Option {
+	return func(c *Client) {
+		if c.resultDecoders == nil {
+			c.resultDecoders = make(map[string]func([]HybridResult) ([]HybridResult, error))
+		}
+		c.resultDecoders[endpoint] = decode
+	}
+}
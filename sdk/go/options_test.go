@@ -0,0 +1,597 @@
+package barqgraphdb
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var paths []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("dry_run") != "true" {
+			t.Errorf("Expected dry_run=true on %s %s", r.Method, r.URL)
+		}
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/nodes":
+			w.Write([]byte(`{"nodes_created":1}`))
+		case "/edges":
+			w.Write([]byte(`{"edges_created":1,"warnings":["node 2 does not exist"]}`))
+		}
+	}, WithDryRun())
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if err := client.AddEdge(1, 2, "OWNS"); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+
+	report := client.DryRunReport()
+	if report.Requests != 2 || report.NodesCreated != 1 || report.EdgesCreated != 1 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0] != "node 2 does not exist" {
+		t.Errorf("unexpected warnings %v", report.Warnings)
+	}
+}
+
+func TestWithoutDryRun(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("dry_run") {
+			t.Errorf("unexpected dry_run param on %s", r.URL)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if report := client.DryRunReport(); report.Requests != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
+
+func TestTransportTuning(t *testing.T) {
+	client := NewClient("http://localhost:3000",
+		WithMaxIdleConnsPerHost(64),
+		WithIdleConnTimeout(5*time.Minute),
+	)
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, got %T", client.httpClient.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != 64 {
+		t.Errorf("Expected MaxIdleConnsPerHost 64, got %d", transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 5*time.Minute {
+		t.Errorf("Expected IdleConnTimeout 5m, got %v", transport.IdleConnTimeout)
+	}
+	if transport == http.DefaultTransport {
+		t.Error("Expected tuning not to modify http.DefaultTransport")
+	}
+}
+
+func TestWithDebugBodies(t *testing.T) {
+	var buf bytes.Buffer
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"created"}`))
+	}, WithDebugBodies(&buf))
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+
+	want := `--> POST /nodes
+{
+  "id": 1,
+  "label": "User"
+}
+<-- 201 POST /nodes
+{
+  "status": "created"
+}
+`
+	if buf.String() != want {
+		t.Errorf("unexpected debug output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWithUniqueLabels(t *testing.T) {
+	labels := map[string]uint64{"User": 1}
+	created := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/nodes/ids":
+			ids := []uint64{}
+			if id, ok := labels[r.URL.Query().Get("label")]; ok {
+				ids = append(ids, id)
+			}
+			writeJSON(w, http.StatusOK, map[string][]uint64{"ids": ids})
+		case r.Method == "POST" && r.URL.Path == "/nodes":
+			var node Node
+			json.NewDecoder(r.Body).Decode(&node)
+			labels[node.Label] = node.ID
+			created++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithUniqueLabels())
+
+	err := client.CreateNode(&Node{ID: 2, Label: "User"})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict for duplicate label, got %v", err)
+	}
+	if err := client.CreateNode(&Node{ID: 3, Label: "Document"}); err != nil {
+		t.Errorf("Expected unique label to be allowed, got %v", err)
+	}
+	if created != 1 {
+		t.Errorf("Expected exactly 1 node created, got %d", created)
+	}
+}
+
+func TestErrorIsConflict(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "node 1 already exists"})
+	})
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected 409 to match ErrConflict, got %v", err)
+	}
+}
+
+func TestWithDefaultEmbedding(t *testing.T) {
+	received := map[uint64][]float32{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var node Node
+		json.NewDecoder(r.Body).Decode(&node)
+		received[node.ID] = node.Embedding
+		w.WriteHeader(http.StatusCreated)
+	}, WithDefaultEmbedding([]float32{0, 0, 0}))
+
+	bare := &Node{ID: 1, Label: "User"}
+	if err := client.CreateNode(bare); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if err := client.CreateNode(&Node{ID: 2, Label: "Doc", Embedding: []float32{0.1, 0.2, 0.3}}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+
+	if got := received[1]; len(got) != 3 || got[0] != 0 {
+		t.Errorf("Expected default embedding for node 1, got %v", got)
+	}
+	if got := received[2]; len(got) != 3 || got[0] != 0.1 {
+		t.Errorf("Expected explicit embedding for node 2, got %v", got)
+	}
+	if bare.Embedding != nil {
+		t.Errorf("Expected caller's node to be left untouched, got %v", bare.Embedding)
+	}
+}
+
+func TestWithEndpointTimeouts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		switch r.URL.Path {
+		case "/query/hybrid":
+			w.Write([]byte(`{"results":[]}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewClientWithTimeout(srv.URL, 50*time.Millisecond, WithEndpointTimeouts(map[string]time.Duration{
+		"/query/hybrid": 2 * time.Second,
+		"/health":       10 * time.Millisecond,
+	}))
+
+	if got := client.timeoutFor("/query/hybrid?explain_plan=true"); got != 2*time.Second {
+		t.Errorf("Expected query string to be ignored, got %v", got)
+	}
+	if _, err := client.HybridQuery(1, []float32{0.1}, 2, 5, DefaultHybridParams()); err != nil {
+		t.Errorf("Expected slow query to succeed under its own timeout, got %v", err)
+	}
+	if _, err := client.Stats(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected default timeout to apply to /stats, got %v", err)
+	}
+	start := time.Now()
+	if _, err := client.Health(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected /health timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 45*time.Millisecond {
+		t.Errorf("Expected /health to use its 10ms timeout, took %v", elapsed)
+	}
+}
+
+func TestWithRequestIDGenerator(t *testing.T) {
+	seen := map[string]bool{}
+	n := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || seen[id] {
+			t.Errorf("Expected a unique request ID, got %q", id)
+		}
+		seen[id] = true
+		if r.URL.Path == "/stats" {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "stats unavailable"})
+			return
+		}
+		w.Write([]byte(`{"status":"healthy"}`))
+	}, WithRequestIDGenerator(func() string {
+		n++
+		return fmt.Sprintf("req-%d", n)
+	}))
+
+	client.Health()
+	client.Health()
+	_, err := client.Stats()
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *Error, got %v", err)
+	}
+	if apiErr.RequestID != "req-3" {
+		t.Errorf("Expected request ID req-3 on the error, got %q", apiErr.RequestID)
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 distinct request IDs, got %d", len(seen))
+	}
+}
+
+func TestWithHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("Expected HTTP/2, got %s", r.Proto)
+		}
+		w.Write([]byte(`{"status":"ok"}`))
+	}))
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(server.URL, WithHTTP2(true))
+	defer client.Close()
+	p := client.transport().Protocols
+	if p == nil || !p.UnencryptedHTTP2() || p.HTTP1() {
+		t.Errorf("Expected an h2c-only transport, got %v", p)
+	}
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health failed: %v", err)
+	}
+}
+
+func TestWithHTTP2OverTLS(t *testing.T) {
+	for _, serverHTTP2 := range []bool{true, false} {
+		var proto int
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proto = r.ProtoMajor
+			w.Write([]byte(`{"status":"ok"}`))
+		}))
+		server.EnableHTTP2 = serverHTTP2
+		server.StartTLS()
+
+		client := NewClient(server.URL, WithHTTP2(true))
+		client.transport().TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+		if _, err := client.Health(); err != nil {
+			t.Errorf("server HTTP/2 %v: Health failed: %v", serverHTTP2, err)
+		}
+		want := 1
+		if serverHTTP2 {
+			want = 2
+		}
+		if proto != want {
+			t.Errorf("server HTTP/2 %v: expected HTTP/%d, got HTTP/%d", serverHTTP2, want, proto)
+		}
+		client.Close()
+		server.Close()
+	}
+}
+
+func TestWithHTTP2Disabled(t *testing.T) {
+	client := NewClient("http://localhost:3000", WithHTTP2(false))
+	p := client.transport().Protocols
+	if p == nil || !p.HTTP1() || p.HTTP2() || p.UnencryptedHTTP2() {
+		t.Errorf("Expected an HTTP/1-only transport, got %v", p)
+	}
+}
+
+func TestWithDecisionNotesTemplate(t *testing.T) {
+	var mu sync.Mutex
+	var notes []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var d Decision
+		json.NewDecoder(r.Body).Decode(&d)
+		mu.Lock()
+		if d.Notes != nil {
+			notes = append(notes, *d.Notes)
+		}
+		mu.Unlock()
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "ok", "decision": d})
+	}, WithDecisionNotesTemplate(func(d *Decision) string {
+		return fmt.Sprintf("agent %d, %d hops", d.AgentID, len(d.Path))
+	}))
+
+	decision := &Decision{AgentID: 7, RootNode: 1, Path: []uint64{1, 2, 3}}
+	if _, err := client.RecordDecision(decision); err != nil {
+		t.Fatalf("RecordDecision failed: %v", err)
+	}
+	if decision.Notes != nil {
+		t.Error("Expected the caller's decision to be left unchanged")
+	}
+	own := "manual notes"
+	if _, err := client.RecordDecision(&Decision{AgentID: 7, RootNode: 1, Notes: &own}); err != nil {
+		t.Fatalf("RecordDecision failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notes) != 2 || notes[0] != "agent 7, 3 hops" || notes[1] != "manual notes" {
+		t.Errorf("Expected templated then manual notes, got %q", notes)
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string]string{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.Method+" "+r.URL.Path] = r.Header.Get("X-Tenant-ID")
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/nodes/1":
+			writeJSON(w, http.StatusOK, Node{ID: 1, Label: "n"})
+		case "/query/hybrid", "/query/vector":
+			w.Write([]byte(`{"results":[]}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}, WithTenant("acme"))
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "n"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if _, err := client.GetNode(1); err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if err := client.AddEdge(1, 2, "LINKS"); err != nil {
+		t.Fatalf("AddEdge failed: %v", err)
+	}
+	if _, err := client.HybridQuery(1, []float32{0.1}, 2, 5, DefaultHybridParams()); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	if _, err := client.VectorSearch([]float32{0.1}, 5); err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 5 {
+		t.Errorf("Expected 5 distinct requests, got %v", seen)
+	}
+	for req, tenant := range seen {
+		if tenant != "acme" {
+			t.Errorf("%s: expected X-Tenant-ID acme, got %q", req, tenant)
+		}
+	}
+}
+
+func TestWithMaxHopsCeiling(t *testing.T) {
+	var mu sync.Mutex
+	var hops []int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req HybridQueryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		hops = append(hops, req.MaxHops)
+		mu.Unlock()
+		w.Write([]byte(`{"results":[]}`))
+	}
+
+	clamp := newTestClient(t, handler, WithMaxHopsCeiling(3, HopsClamp))
+	if _, err := clamp.HybridQuery(1, []float32{0.1}, 10, 5, DefaultHybridParams()); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	if _, err := clamp.HybridQuery(1, []float32{0.1}, 2, 5, DefaultHybridParams()); err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+
+	reject := newTestClient(t, handler, WithMaxHopsCeiling(3, HopsReject))
+	if _, err := reject.HybridQuery(1, []float32{0.1}, 10, 5, DefaultHybridParams()); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := reject.HybridQuery(1, []float32{0.1}, 3, 5, DefaultHybridParams()); err != nil {
+		t.Fatalf("HybridQuery at the ceiling failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hops) != 3 || hops[0] != 3 || hops[1] != 2 || hops[2] != 3 {
+		t.Errorf("Expected max_hops [3 2 3] sent, got %v", hops)
+	}
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"label":"n","shard":7}`))
+	}
+
+	lenient := newTestClient(t, handler)
+	if _, err := lenient.GetNode(1); err != nil {
+		t.Errorf("Expected unknown fields to be ignored by default, got %v", err)
+	}
+
+	strict := newTestClient(t, handler, WithStrictDecoding())
+	if _, err := strict.GetNode(1); err == nil || !strings.Contains(err.Error(), "shard") {
+		t.Errorf("Expected an unknown field error under strict decoding, got %v", err)
+	}
+
+	cached := newTestClient(t, handler, WithStrictDecoding(), WithCache(time.Minute))
+	for i := 0; i < 2; i++ {
+		if _, err := cached.GetNode(1); err == nil {
+			t.Errorf("attempt %d: expected an unknown field error from the cached path", i+1)
+		}
+	}
+}
+
+func TestWithRequestSigner(t *testing.T) {
+	secret := []byte("s3cret")
+	sign := func(method, path string, body []byte) string {
+		mac := hmac.New(sha256.New, secret)
+		fmt.Fprintf(mac, "%s\n%s\n", method, path)
+		mac.Write(body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	var checked int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if got, want := r.Header.Get("X-Signature"), sign(r.Method, r.URL.RequestURI(), body); got != want {
+			t.Errorf("%s %s: expected signature %s, got %s", r.Method, r.URL.Path, want, got)
+		}
+		checked++
+		if r.Method == "GET" {
+			writeJSON(w, http.StatusOK, Node{ID: 1})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}, WithRequestSigner(func(req *http.Request, body []byte) error {
+		req.Header.Set("X-Signature", sign(req.Method, req.URL.RequestURI(), body))
+		return nil
+	}))
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "signed"}); err != nil {
+		t.Fatalf("CreateNode failed: %v", err)
+	}
+	if _, err := client.GetNode(1); err != nil {
+		t.Fatalf("GetNode failed: %v", err)
+	}
+	if checked != 2 {
+		t.Errorf("Expected 2 signed requests, got %d", checked)
+	}
+}
+
+func TestWithRequestSignerError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}, WithRequestSigner(func(req *http.Request, body []byte) error {
+		return errors.New("key unavailable")
+	}))
+
+	if err := client.CreateNode(&Node{ID: 1}); err == nil || !strings.Contains(err.Error(), "key unavailable") {
+		t.Errorf("Expected signing error, got %v", err)
+	}
+}
+
+func TestWithAutoTouch(t *testing.T) {
+	var bodies []map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/nodes/1" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+	}
+
+	node := &Node{ID: 1, Label: "n"}
+	if err := newTestClient(t, handler).UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+	before := time.Now().Unix()
+	if err := newTestClient(t, handler, WithAutoTouch()).UpdateNode(node); err != nil {
+		t.Fatalf("UpdateNode failed: %v", err)
+	}
+
+	if _, ok := bodies[0]["updated_at"]; ok {
+		t.Errorf("Expected no updated_at without WithAutoTouch, got %v", bodies[0])
+	}
+	ts, ok := bodies[1]["updated_at"].(float64)
+	if !ok || int64(ts) < before || int64(ts) > time.Now().Unix() {
+		t.Errorf("Expected updated_at to be the current time, got %v", bodies[1]["updated_at"])
+	}
+	if node.UpdatedAt != nil {
+		t.Errorf("Expected caller's node to be left alone, got UpdatedAt %v", *node.UpdatedAt)
+	}
+}
+
+func TestWithResultDecoder(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/query/hybrid" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"results": []map[string]interface{}{
+				{"id": 1, "score": 0.9, "graph_distance": 1},
+				{"id": 2, "score": 0.2, "graph_distance": 3},
+				{"id": 3, "score": 0.7, "graph_distance": 2},
+			},
+		})
+	}
+	decode := func(results []HybridResult) ([]HybridResult, error) {
+		var kept []HybridResult
+		for _, r := range results {
+			if r.Score >= 0.5 {
+				r.Score *= 10
+				kept = append(kept, r)
+			}
+		}
+		return kept, nil
+	}
+
+	client := newTestClient(t, handler, WithResultDecoder("/query/hybrid", decode))
+	results, err := client.HybridQuery(1, []float32{1, 0}, 3, 10, HybridParams{Alpha: 0.5, Beta: 0.5})
+	if err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != 1 || results[1].ID != 3 {
+		t.Fatalf("Expected results 1 and 3, got %+v", results)
+	}
+	if results[0].Score != 9 {
+		t.Errorf("Expected enriched score 9, got %v", results[0].Score)
+	}
+
+	other := newTestClient(t, handler, WithResultDecoder("/query/vector", decode))
+	results, err = other.HybridQuery(1, []float32{1, 0}, 3, 10, HybridParams{Alpha: 0.5, Beta: 0.5})
+	if err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected hook for another endpoint to be ignored, got %+v", results)
+	}
+}
+
+func TestWithResultDecoderError(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": []map[string]interface{}{{"id": 1}}})
+	}
+	failure := errors.New("enrichment failed")
+	client := newTestClient(t, handler, WithResultDecoder("/query/hybrid", func([]HybridResult) ([]HybridResult, error) {
+		return nil, failure
+	}))
+
+	if _, err := client.HybridQuery(1, []float32{1}, 1, 1, HybridParams{Alpha: 1}); !errors.Is(err, failure) {
+		t.Errorf("Expected decoder error, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package barqgraphdb
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultEjectionPeriod is how long a failing endpoint is skipped before it
+// is tried again.
+const defaultEjectionPeriod = 30 * time.Second
+
+// NewClientPool creates a client that spreads requests round-robin across
+// several server endpoints of a clustered deployment. An endpoint that fails
+// with a network error or a 5xx response is ejected for a while so later
+// requests go to its healthy peers; if every endpoint is ejected, the one
+// due back soonest is used. baseURLs must not be empty.
+func NewClientPool(baseURLs []string, opts ...Option) *Client {
+	var first string
+	if len(baseURLs) > 0 {
+		first = baseURLs[0]
+	}
+	c := NewClient(first, opts...)
+	if len(baseURLs) > 1 {
+		c.pool = newEndpointPool(baseURLs)
+	}
+	return c
+}
+
+// poolMember is one endpoint of an endpointPool.
+type poolMember struct {
+	baseURL      string
+	ejectedUntil time.Time
+}
+
+// endpointPool selects endpoints round-robin, skipping ejected ones.
+type endpointPool struct {
+	mu        sync.Mutex
+	endpoints []*poolMember
+	next      int
+	ejectFor  time.Duration
+}
+
+func newEndpointPool(baseURLs []string) *endpointPool {
+	p := &endpointPool{ejectFor: defaultEjectionPeriod}
+	for _, u := range baseURLs {
+		p.endpoints = append(p.endpoints, &poolMember{baseURL: u})
+	}
+	return p
+}
+
+// pick returns the next healthy endpoint in round-robin order.
+func (p *endpointPool) pick() *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var soonest *poolMember
+	for i := 0; i < len(p.endpoints); i++ {
+		e := p.endpoints[(p.next+i)%len(p.endpoints)]
+		if !now.Before(e.ejectedUntil) {
+			p.next = (p.next + i + 1) % len(p.endpoints)
+			return e
+		}
+		if soonest == nil || e.ejectedUntil.Before(soonest.ejectedUntil) {
+			soonest = e
+		}
+	}
+	return soonest
+}
+
+// report records the outcome of a request sent to e, ejecting it on failure
+// and reinstating it on success.
+func (p *endpointPool) report(e *poolMember, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ok {
+		e.ejectedUntil = time.Time{}
+	} else {
+		e.ejectedUntil = time.Now().Add(p.ejectFor)
+	}
+}
+
+// EndpointHealth is the result of checking one endpoint's health.
+type EndpointHealth struct {
+	BaseURL string
+	// Healthy reports whether /health answered successfully; if not, Err
+	// says why.
+	Healthy bool
+	Status  string
+	Version string
+	Latency time.Duration
+	Err     error
+}
+
+// ClusterHealth checks /health on every endpoint concurrently, for
+// monitoring a clustered deployment. The results are in the order of
+// baseURLs and always cover every endpoint, with each unhealthy endpoint's
+// failure in its Err; an unhealthy endpoint is not an error of the call.
+// ClusterHealth returns ErrInvalidArgument if baseURLs is empty. opts
+// configure the clients used for the checks.
+func ClusterHealth(baseURLs []string, opts ...Option) ([]EndpointHealth, error) {
+	if len(baseURLs) == 0 {
+		return nil, fmt.Errorf("%w: no endpoints to check", ErrInvalidArgument)
+	}
+	results := make([]EndpointHealth, len(baseURLs))
+	var wg sync.WaitGroup
+	for i, baseURL := range baseURLs {
+		wg.Add(1)
+		go func(i int, baseURL string) {
+			defer wg.Done()
+			client := NewClient(baseURL, opts...)
+			defer client.Close()
+
+			start := time.Now()
+			health, err := client.Health()
+			results[i] = EndpointHealth{BaseURL: baseURL, Latency: time.Since(start), Err: err}
+			if err == nil {
+				results[i].Healthy = true
+				results[i].Status = health.Status
+				results[i].Version = health.Version
+			}
+		}(i, baseURL)
+	}
+	wg.Wait()
+	return results, nil
+}
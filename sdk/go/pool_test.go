@@ -0,0 +1,123 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientPoolDistributesRequests(t *testing.T) {
+	counts := make([]int, 3)
+	var urls []string
+	for i := range counts {
+		i := i
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			counts[i]++
+			w.Write([]byte(`{"status":"healthy"}`))
+		}))
+		t.Cleanup(srv.Close)
+		urls = append(urls, srv.URL)
+	}
+
+	client := NewClientPool(urls)
+	for i := 0; i < 9; i++ {
+		if _, err := client.Health(); err != nil {
+			t.Fatalf("Health failed: %v", err)
+		}
+	}
+	for i, n := range counts {
+		if n != 3 {
+			t.Errorf("Expected endpoint %d to serve 3 requests, got %d", i, n)
+		}
+	}
+}
+
+func TestClientPoolSkipsFailingEndpoint(t *testing.T) {
+	healthy := 0
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthy++
+		w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	t.Cleanup(good.Close)
+
+	failing := 0
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failing++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(bad.Close)
+
+	client := NewClientPool([]string{bad.URL, good.URL})
+
+	// The first request lands on the failing endpoint and ejects it.
+	if _, err := client.Health(); err == nil {
+		t.Fatal("Expected the first request to fail")
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := client.Health(); err != nil {
+			t.Fatalf("Health failed after ejection: %v", err)
+		}
+	}
+	if failing != 1 {
+		t.Errorf("Expected the failing endpoint to be skipped, got %d requests", failing)
+	}
+	if healthy != 5 {
+		t.Errorf("Expected 5 requests on the healthy endpoint, got %d", healthy)
+	}
+}
+
+func TestClusterHealth(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"status":"healthy","version":"1.2.0"}`))
+	}))
+	t.Cleanup(healthy.Close)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"starting up"}`))
+	}))
+	t.Cleanup(failing.Close)
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	results, err := ClusterHealth([]string{healthy.URL, down.URL, failing.URL})
+	if err != nil {
+		t.Errorf("Expected unhealthy endpoints to be reported only in their results, got %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Healthy || results[0].Version != "1.2.0" || results[0].Latency <= 0 || results[0].BaseURL != healthy.URL {
+		t.Errorf("Expected endpoint 0 healthy, got %+v", results[0])
+	}
+	if results[1].Healthy || results[1].Err == nil {
+		t.Errorf("Expected endpoint 1 unreachable, got %+v", results[1])
+	}
+	if results[2].Healthy || results[2].Err == nil {
+		t.Errorf("Expected endpoint 2 unhealthy, got %+v", results[2])
+	}
+}
+
+func TestClusterHealthAllHealthy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"healthy"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	results, err := ClusterHealth([]string{srv.URL, srv.URL})
+	if err != nil {
+		t.Fatalf("ClusterHealth failed: %v", err)
+	}
+	if len(results) != 2 || !results[0].Healthy || !results[1].Healthy {
+		t.Errorf("Expected two healthy endpoints, got %+v", results)
+	}
+}
+
+func TestClusterHealthNoEndpoints(t *testing.T) {
+	if _, err := ClusterHealth(nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// ShortestPathWeighted finds the path from one node to another that
+// minimizes the total edge weight rather than the hop count, and returns it
+// along with its total cost. It returns ErrNoPath if to is unreachable and
+// ErrUnsupportedFeature if the server lacks weighted edge support.
+func (c *Client) ShortestPathWeighted(from, to uint64) ([]uint64, float32, error) {
+	if err := c.requireFeature(FeatureWeightedEdges); err != nil {
+		return nil, 0, err
+	}
+	payload := struct {
+		From uint64 `json:"from"`
+		To   uint64 `json:"to"`
+	}{
+		From: from,
+		To:   to,
+	}
+	var result struct {
+		Path []uint64 `json:"path"`
+		Cost float32  `json:"cost"`
+	}
+	if err := c.doRequest("POST", "/query/path?weighted=true", payload, &result); err != nil {
+		return nil, 0, err
+	}
+	if len(result.Path) == 0 {
+		return nil, 0, ErrNoPath
+	}
+	return result.Path, result.Cost, nil
+}
+
+// QueryPlan is the server's execution plan for a hybrid query.
+type QueryPlan struct {
+	IndexUsed     string     `json:"index_used"`
+	NodesVisited  int        `json:"nodes_visited"`
+	EstimatedCost float32    `json:"estimated_cost"`
+	Steps         []PlanStep `json:"steps"`
+}
+
+// PlanStep is one stage of a QueryPlan.
+type PlanStep struct {
+	Operation     string  `json:"operation"`
+	Detail        string  `json:"detail,omitempty"`
+	NodesVisited  int     `json:"nodes_visited"`
+	EstimatedCost float32 `json:"estimated_cost"`
+}
+
+// ExplainHybridQuery returns the plan the server would use to run a hybrid
+// query, without running it. It is useful for tuning alpha, beta and
+// maxHops.
+func (c *Client) ExplainHybridQuery(start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) (*QueryPlan, error) {
+	req, err := c.newHybridQueryRequest(start, queryEmbedding, maxHops, k, params)
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Plan QueryPlan `json:"plan"`
+	}
+	err = c.doRequest("POST", exactParam("/query/hybrid?explain_plan=true", req.Exact), req, &result)
+	return &result.Plan, err
+}
+
+// NearestStoredNodes returns, for each query vector, the IDs of the k
+// nearest stored nodes, in one request. result[i] answers queryEmbeddings[i].
+func (c *Client) NearestStoredNodes(queryEmbeddings [][]float32, k int) ([][]uint64, error) {
+	payload := struct {
+		Queries [][]float32 `json:"queries"`
+		K       int         `json:"k"`
+		IDsOnly bool        `json:"ids_only"`
+	}{
+		Queries: queryEmbeddings,
+		K:       k,
+		IDsOnly: true,
+	}
+	var result struct {
+		Results [][]uint64 `json:"results"`
+	}
+	if err := c.doRequest("POST", "/query/vector/batch", payload, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) != len(queryEmbeddings) {
+		return nil, fmt.Errorf("barqgraphdb: expected %d result sets, got %d", len(queryEmbeddings), len(result.Results))
+	}
+	return result.Results, nil
+}
+
+// VectorSearchRequest represents a pure vector similarity search request.
+type VectorSearchRequest struct {
+	QueryEmbedding []float32 `json:"query_embedding"`
+	K              int       `json:"k"`
+	MinScore       *float32  `json:"min_score,omitempty"`
+	Metric         string    `json:"metric,omitempty"`
+
+	// Exact is sent as the exact query parameter, not in the body.
+	Exact bool `json:"-"`
+}
+
+// SearchOption adjusts a vector search.
+type SearchOption func(*VectorSearchRequest)
+
+// ExactSearch asks for an exhaustive search instead of the default
+// approximate index lookup, trading latency for recall.
+func ExactSearch(exact bool) SearchOption {
+	return func(req *VectorSearchRequest) {
+		req.Exact = exact
+	}
+}
+
+// exactParam adds the exact query parameter selecting exhaustive (true) or
+// approximate (false) vector search to an endpoint.
+func exactParam(endpoint string, exact bool) string {
+	return withQueryParam(endpoint, "exact", strconv.FormatBool(exact))
+}
+
+// VectorSearch returns the k stored nodes most similar to queryEmbedding.
+// The search is approximate unless ExactSearch(true) is given.
+func (c *Client) VectorSearch(queryEmbedding []float32, k int, opts ...SearchOption) ([]HybridResult, error) {
+	return c.VectorSearchContext(context.Background(), queryEmbedding, k, opts...)
+}
+
+// VectorSearchContext is VectorSearch using ctx.
+func (c *Client) VectorSearchContext(ctx context.Context, queryEmbedding []float32, k int, opts ...SearchOption) ([]HybridResult, error) {
+	req := VectorSearchRequest{QueryEmbedding: queryEmbedding, K: k}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	return c.vectorSearch(ctx, req)
+}
+
+// FindAboveThreshold returns every stored node whose similarity to query is
+// at least threshold, up to maxResults, instead of a fixed number of
+// neighbours. The result is empty, not an error, when nothing qualifies.
+func (c *Client) FindAboveThreshold(query []float32, threshold float32, maxResults int) ([]HybridResult, error) {
+	return c.vectorSearch(context.Background(), VectorSearchRequest{QueryEmbedding: query, K: maxResults, MinScore: &threshold})
+}
+
+func (c *Client) vectorSearch(ctx context.Context, req VectorSearchRequest) ([]HybridResult, error) {
+	if req.Metric == "" {
+		req.Metric = c.distanceMetric
+	}
+	var result struct {
+		Results []HybridResult `json:"results"`
+	}
+	if err := c.doRequestContext(ctx, "POST", exactParam("/query/vector", req.Exact), req, &result); err != nil {
+		return nil, err
+	}
+	results, err := c.decodeResults("/query/vector", result.Results)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []HybridResult{}
+	}
+	return results, nil
+}
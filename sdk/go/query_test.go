@@ -0,0 +1,250 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestShortestPathWeighted(t *testing.T) {
+	// Fixture: 1->4 directly with weight 10, or 1->2->3->4 with weight 1 each.
+	// The fewest-hops path is [1 4]; the lowest-cost path is [1 2 3 4].
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			w.Write([]byte(`{"features":["weighted_edges"]}`))
+			return
+		}
+		if r.Method != "POST" || r.URL.Path != "/query/path" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			From uint64 `json:"from"`
+			To   uint64 `json:"to"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.From != 1 || body.To != 4 {
+			t.Errorf("unexpected body %+v", body)
+		}
+		if r.URL.Query().Get("weighted") == "true" {
+			w.Write([]byte(`{"path":[1,2,3,4],"cost":3}`))
+			return
+		}
+		w.Write([]byte(`{"path":[1,4],"cost":1}`))
+	})
+
+	path, cost, err := client.ShortestPathWeighted(1, 4)
+	if err != nil {
+		t.Fatalf("ShortestPathWeighted failed: %v", err)
+	}
+	if len(path) != 4 || path[1] != 2 || path[2] != 3 {
+		t.Errorf("Expected lowest-cost path [1 2 3 4], got %v", path)
+	}
+	if cost != 3 {
+		t.Errorf("Expected cost 3, got %v", cost)
+	}
+}
+
+func TestShortestPathWeightedUnreachable(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			w.Write([]byte(`{"features":["weighted_edges"]}`))
+			return
+		}
+		w.Write([]byte(`{"path":[],"cost":0}`))
+	})
+
+	_, _, err := client.ShortestPathWeighted(1, 99)
+	if !errors.Is(err, ErrNoPath) {
+		t.Errorf("Expected ErrNoPath, got %v", err)
+	}
+}
+
+func TestExplainHybridQuery(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/query/hybrid" || r.URL.Query().Get("explain_plan") != "true" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req HybridQueryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Start != 1 || req.MaxHops != 3 || req.K != 5 || req.Alpha != 0.7 {
+			t.Errorf("unexpected query %+v", req)
+		}
+		w.Write([]byte(`{"plan":{
+			"index_used": "hnsw",
+			"nodes_visited": 42,
+			"estimated_cost": 12.5,
+			"steps": [
+				{"operation": "bfs", "detail": "max_hops=3", "nodes_visited": 30, "estimated_cost": 3},
+				{"operation": "vector_scan", "nodes_visited": 12, "estimated_cost": 9.5}
+			]
+		}}`))
+	})
+
+	plan, err := client.ExplainHybridQuery(1, []float32{0.1, 0.2}, 3, 5, HybridParams{Alpha: 0.7, Beta: 0.3})
+	if err != nil {
+		t.Fatalf("ExplainHybridQuery failed: %v", err)
+	}
+	if plan.IndexUsed != "hnsw" || plan.NodesVisited != 42 || plan.EstimatedCost != 12.5 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if len(plan.Steps) != 2 || plan.Steps[0].Operation != "bfs" || plan.Steps[1].NodesVisited != 12 {
+		t.Errorf("unexpected steps %+v", plan.Steps)
+	}
+}
+
+func TestNearestStoredNodes(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/query/vector/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Queries [][]float32 `json:"queries"`
+			K       int         `json:"k"`
+			IDsOnly bool        `json:"ids_only"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Queries) != 3 || body.K != 2 || !body.IDsOnly {
+			t.Errorf("unexpected body %+v", body)
+		}
+		w.Write([]byte(`{"results":[[4,9],[1,2],[]]}`))
+	})
+
+	results, err := client.NearestStoredNodes([][]float32{{1, 0}, {0, 1}, {0.5, 0.5}}, 2)
+	if err != nil {
+		t.Fatalf("NearestStoredNodes failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 result sets, got %d", len(results))
+	}
+	if results[0][0] != 4 || results[0][1] != 9 || results[1][0] != 1 || len(results[2]) != 0 {
+		t.Errorf("unexpected results %v", results)
+	}
+}
+
+func TestFindAboveThreshold(t *testing.T) {
+	stored := []HybridResult{{ID: 1, Score: 0.95}, {ID: 2, Score: 0.81}, {ID: 3, Score: 0.40}}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/query/vector" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req VectorSearchRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.MinScore == nil {
+			t.Fatal("Expected min_score in request")
+		}
+		results := []HybridResult{}
+		for _, s := range stored {
+			if s.Score >= *req.MinScore && len(results) < req.K {
+				results = append(results, s)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"results": results})
+	})
+
+	results, err := client.FindAboveThreshold([]float32{0.1, 0.2}, 0.8, 10)
+	if err != nil {
+		t.Fatalf("FindAboveThreshold failed: %v", err)
+	}
+	if len(results) != 2 || results[0].ID != 1 || results[1].ID != 2 {
+		t.Errorf("Expected nodes 1 and 2, got %+v", results)
+	}
+
+	results, err = client.FindAboveThreshold([]float32{0.1, 0.2}, 0.99, 10)
+	if err != nil {
+		t.Fatalf("FindAboveThreshold failed: %v", err)
+	}
+	if results == nil || len(results) != 0 {
+		t.Errorf("Expected empty non-nil result, got %#v", results)
+	}
+}
+
+func TestVectorSearchOmitsMinScore(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["min_score"]; ok {
+			t.Errorf("Expected no min_score, got %v", body)
+		}
+		if body["k"] != float64(3) {
+			t.Errorf("Expected k=3, got %v", body["k"])
+		}
+		w.Write([]byte(`{"results":[{"id":7,"score":0.9}]}`))
+	})
+
+	results, err := client.VectorSearch([]float32{0.1}, 3)
+	if err != nil {
+		t.Fatalf("VectorSearch failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != 7 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestHybridQueryPureGraph(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["query_embedding"]; ok {
+			t.Errorf("Expected no query_embedding for a pure graph query, got %v", body)
+		}
+		if body["alpha"] != float64(0) || body["beta"] != float64(1) {
+			t.Errorf("unexpected weights %v", body)
+		}
+		w.Write([]byte(`{"results":[{"id":2,"score":1,"graph_distance":1,"path":[1,2]}]}`))
+	})
+
+	results, err := client.HybridQuery(1, nil, 2, 5, HybridParams{Alpha: 0, Beta: 1})
+	if err != nil {
+		t.Fatalf("HybridQuery failed: %v", err)
+	}
+	if len(results) != 1 || results[0].GraphDistance != 1 {
+		t.Errorf("unexpected results %+v", results)
+	}
+}
+
+func TestHybridQueryRequiresEmbeddingWithAlpha(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Expected no request, got %s", r.URL)
+	})
+
+	_, err := client.HybridQuery(1, []float32{}, 2, 5, DefaultHybridParams())
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestExactSearchParam(t *testing.T) {
+	var got []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["exact"]; ok {
+			t.Errorf("Expected exact only in the query string, got body %v", body)
+		}
+		got = append(got, r.URL.Path+" exact="+r.URL.Query().Get("exact"))
+		w.Write([]byte(`{"results":[]}`))
+	})
+
+	client.VectorSearch([]float32{0.1}, 3)
+	client.VectorSearch([]float32{0.1}, 3, ExactSearch(true))
+	client.HybridQuery(1, []float32{0.1}, 2, 3, DefaultHybridParams())
+	params := DefaultHybridParams()
+	params.Exact = true
+	client.HybridQuery(1, []float32{0.1}, 2, 3, params)
+
+	want := []string{
+		"/query/vector exact=false",
+		"/query/vector exact=true",
+		"/query/hybrid exact=false",
+		"/query/hybrid exact=true",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package barqgraphdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// reembedBatchSize is both the page size used to walk the graph and the
+// number of embeddings uploaded per request while reembedding.
+const reembedBatchSize = 100
+
+// ReembedOption configures ReembedAll.
+type ReembedOption func(*reembedConfig)
+
+type reembedConfig struct {
+	progress func(done int)
+}
+
+// ReembedProgress calls fn after each uploaded batch with the number of
+// nodes reembedded so far.
+func ReembedProgress(fn func(done int)) ReembedOption {
+	return func(cfg *reembedConfig) {
+		cfg.progress = fn
+	}
+}
+
+// embeddingUpdate is one entry of a batch embedding upload.
+type embeddingUpdate struct {
+	ID        uint64    `json:"id"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// ReembedAll walks every node, asks compute for its new embedding and
+// uploads the results in batches via PUT /embeddings/batch, returning how
+// many nodes were reembedded. Use it to re-vectorize a graph after switching
+// embedding models.
+//
+// A compute error, a failed upload or cancellation of ctx stops the
+// migration; the returned count covers the batches uploaded before that, so
+// a rerun repeats at most one batch.
+func (c *Client) ReembedAll(ctx context.Context, compute func(node Node) ([]float32, error), opts ...ReembedOption) (int, error) {
+	var cfg reembedConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	done := 0
+	batch := make([]embeddingUpdate, 0, reembedBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		payload := struct {
+			Embeddings []embeddingUpdate `json:"embeddings"`
+		}{
+			Embeddings: batch,
+		}
+		if err := c.doMutationContext(ctx, "PUT", "/embeddings/batch", payload, nil); err != nil {
+			return err
+		}
+		for _, update := range batch {
+			c.InvalidateNode(update.ID)
+		}
+		done += len(batch)
+		batch = batch[:0]
+		if cfg.progress != nil {
+			cfg.progress(done)
+		}
+		return nil
+	}
+
+	for node, err := range c.AllNodes(reembedBatchSize) {
+		if err != nil {
+			return done, err
+		}
+		if err := ctx.Err(); err != nil {
+			return done, err
+		}
+		embedding, err := compute(node)
+		if err != nil {
+			return done, fmt.Errorf("failed to compute embedding for node %d: %w", node.ID, err)
+		}
+		batch = append(batch, embeddingUpdate{ID: node.ID, Embedding: embedding})
+		if len(batch) == reembedBatchSize {
+			if err := flush(); err != nil {
+				return done, err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return done, err
+	}
+	return done, flush()
+}
@@ -0,0 +1,111 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// reembedServer serves n nodes and records uploaded embeddings by node ID.
+type reembedServer struct {
+	mu       sync.Mutex
+	uploads  int
+	received map[uint64][]float32
+}
+
+func (s *reembedServer) handler(t *testing.T, n int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/nodes":
+			q := r.URL.Query()
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			limit, _ := strconv.Atoi(q.Get("limit"))
+			nodes := []Node{}
+			for i := offset; i < n && i < offset+limit; i++ {
+				nodes = append(nodes, Node{ID: uint64(i + 1), Label: "n"})
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": nodes})
+		case r.Method == "PUT" && r.URL.Path == "/embeddings/batch":
+			var body struct {
+				Embeddings []embeddingUpdate `json:"embeddings"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			s.mu.Lock()
+			s.uploads++
+			for _, update := range body.Embeddings {
+				s.received[update.ID] = update.Embedding
+			}
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestReembedAll(t *testing.T) {
+	srv := &reembedServer{received: map[uint64][]float32{}}
+	client := newTestClient(t, srv.handler(t, 150))
+
+	var progress []int
+	count, err := client.ReembedAll(context.Background(), func(node Node) ([]float32, error) {
+		return []float32{float32(node.ID), 1}, nil
+	}, ReembedProgress(func(done int) { progress = append(progress, done) }))
+	if err != nil {
+		t.Fatalf("ReembedAll failed: %v", err)
+	}
+	if count != 150 {
+		t.Errorf("Expected 150 nodes reembedded, got %d", count)
+	}
+	if srv.uploads != 2 {
+		t.Errorf("Expected 2 batch uploads, got %d", srv.uploads)
+	}
+	if len(progress) != 2 || progress[0] != 100 || progress[1] != 150 {
+		t.Errorf("Expected progress [100 150], got %v", progress)
+	}
+	if got := srv.received[42]; len(got) != 2 || got[0] != 42 {
+		t.Errorf("Expected node 42 to get [42 1], got %v", got)
+	}
+}
+
+func TestReembedAllComputeError(t *testing.T) {
+	srv := &reembedServer{received: map[uint64][]float32{}}
+	client := newTestClient(t, srv.handler(t, 5))
+
+	boom := errors.New("model unavailable")
+	count, err := client.ReembedAll(context.Background(), func(node Node) ([]float32, error) {
+		if node.ID == 3 {
+			return nil, boom
+		}
+		return []float32{1}, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Expected compute error, got %v", err)
+	}
+	if count != 0 || srv.uploads != 0 {
+		t.Errorf("Expected nothing uploaded, got count %d and %d uploads", count, srv.uploads)
+	}
+}
+
+func TestReembedAllCanceled(t *testing.T) {
+	srv := &reembedServer{received: map[uint64][]float32{}}
+	client := newTestClient(t, srv.handler(t, 150))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	count, err := client.ReembedAll(ctx, func(node Node) ([]float32, error) {
+		if node.ID == 120 {
+			cancel()
+		}
+		return []float32{1}, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if count != 100 {
+		t.Errorf("Expected the first batch to be counted, got %d", count)
+	}
+}
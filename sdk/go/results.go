@@ -0,0 +1,112 @@
+package barqgraphdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// decodeResults passes results decoded from endpoint through the hook
+// registered for it with WithResultDecoder, if any.
+func (c *Client) decodeResults(endpoint string, results []HybridResult) ([]HybridResult, error) {
+	decode, ok := c.resultDecoders[endpoint]
+	if !ok {
+		return results, nil
+	}
+	decoded, err := decode(results)
+	if err != nil {
+		return nil, fmt.Errorf("result decoder for %s: %w", endpoint, err)
+	}
+	return decoded, nil
+}
+
+// DedupeByPathPrefix collapses hybrid results whose paths share the same
+// first prefixLen node IDs, keeping only the highest-scoring result of each
+// group. Paths shorter than prefixLen are grouped by their full path. The
+// surviving results keep their original relative order. A prefixLen below 1
+// returns the results unchanged.
+func DedupeByPathPrefix(results []HybridResult, prefixLen int) []HybridResult {
+	if prefixLen < 1 {
+		return append([]HybridResult(nil), results...)
+	}
+
+	best := make(map[string]int)
+	var keys []string
+	for i, r := range results {
+		key := pathPrefixKey(r.Path, prefixLen)
+		j, seen := best[key]
+		if !seen {
+			keys = append(keys, key)
+		}
+		if !seen || r.Score > results[j].Score {
+			best[key] = i
+		}
+	}
+
+	kept := make([]bool, len(results))
+	for _, key := range keys {
+		kept[best[key]] = true
+	}
+	deduped := make([]HybridResult, 0, len(keys))
+	for i, r := range results {
+		if kept[i] {
+			deduped = append(deduped, r)
+		}
+	}
+	return deduped
+}
+
+func pathPrefixKey(path []uint64, prefixLen int) string {
+	if len(path) > prefixLen {
+		path = path[:prefixLen]
+	}
+	var b strings.Builder
+	for i, id := range path {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString(strconv.FormatUint(id, 10))
+	}
+	return b.String()
+}
+
+// ScoreHistogram counts result scores in equal-width buckets spanning
+// [Min, Max]. Bucket i covers [Min+i*Width, Min+(i+1)*Width); the last
+// bucket also includes Max.
+type ScoreHistogram struct {
+	Min    float32
+	Max    float32
+	Width  float32
+	Counts []int
+}
+
+// QueryScoreHistogram buckets the scores of results, for calibrating score
+// thresholds across datasets. If every score is equal they all land in the
+// first bucket. It returns an empty histogram for no results or buckets < 1.
+func QueryScoreHistogram(results []HybridResult, buckets int) ScoreHistogram {
+	if len(results) == 0 || buckets < 1 {
+		return ScoreHistogram{}
+	}
+
+	h := ScoreHistogram{Min: results[0].Score, Max: results[0].Score, Counts: make([]int, buckets)}
+	for _, r := range results[1:] {
+		if r.Score < h.Min {
+			h.Min = r.Score
+		}
+		if r.Score > h.Max {
+			h.Max = r.Score
+		}
+	}
+	h.Width = (h.Max - h.Min) / float32(buckets)
+	for _, r := range results {
+		i := 0
+		if h.Width > 0 {
+			i = int((r.Score - h.Min) / h.Width)
+		}
+		if i >= buckets {
+			i = buckets - 1
+		}
+		h.Counts[i]++
+	}
+	return h
+}
@@ -0,0 +1,75 @@
+package barqgraphdb
+
+import "testing"
+
+func TestDedupeByPathPrefix(t *testing.T) {
+	results := []HybridResult{
+		{ID: 3, Score: 0.60, Path: []uint64{1, 2, 3}},
+		{ID: 4, Score: 0.90, Path: []uint64{1, 2, 4}},
+		{ID: 5, Score: 0.70, Path: []uint64{1, 5}},
+		{ID: 6, Score: 0.80, Path: []uint64{1, 5, 6}},
+		{ID: 7, Score: 0.50, Path: []uint64{1, 7}},
+		{ID: 8, Score: 0.40, Path: []uint64{1}},
+	}
+
+	deduped := DedupeByPathPrefix(results, 2)
+	var ids []uint64
+	for _, r := range deduped {
+		ids = append(ids, r.ID)
+	}
+	want := []uint64{4, 6, 7, 8}
+	if len(ids) != len(want) {
+		t.Fatalf("Expected IDs %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("Expected IDs %v, got %v", want, ids)
+			break
+		}
+	}
+}
+
+func TestDedupeByPathPrefixDisabled(t *testing.T) {
+	results := []HybridResult{
+		{ID: 2, Score: 0.5, Path: []uint64{1, 2}},
+		{ID: 3, Score: 0.6, Path: []uint64{1, 3}},
+	}
+	if got := DedupeByPathPrefix(results, 0); len(got) != 2 {
+		t.Errorf("Expected results unchanged, got %v", got)
+	}
+	if got := DedupeByPathPrefix(nil, 2); len(got) != 0 {
+		t.Errorf("Expected no results, got %v", got)
+	}
+}
+
+func TestQueryScoreHistogram(t *testing.T) {
+	scores := []float32{0, 0.1, 0.2, 0.3, 0.45, 0.5, 0.9, 1.0}
+	results := make([]HybridResult, len(scores))
+	for i, s := range scores {
+		results[i] = HybridResult{ID: uint64(i), Score: s}
+	}
+
+	h := QueryScoreHistogram(results, 4)
+	if h.Min != 0 || h.Max != 1 || h.Width != 0.25 {
+		t.Errorf("Expected range [0,1] width 0.25, got %+v", h)
+	}
+	want := []int{3, 2, 1, 2}
+	for i, n := range want {
+		if h.Counts[i] != n {
+			t.Errorf("Expected counts %v, got %v", want, h.Counts)
+			break
+		}
+	}
+}
+
+func TestQueryScoreHistogramDegenerate(t *testing.T) {
+	if h := QueryScoreHistogram(nil, 4); h.Counts != nil {
+		t.Errorf("Expected empty histogram, got %+v", h)
+	}
+
+	results := []HybridResult{{ID: 1, Score: 0.5}, {ID: 2, Score: 0.5}}
+	h := QueryScoreHistogram(results, 3)
+	if h.Counts[0] != 2 || h.Counts[1] != 0 || h.Counts[2] != 0 {
+		t.Errorf("Expected equal scores in the first bucket, got %v", h.Counts)
+	}
+}
@@ -0,0 +1,74 @@
+package barqgraphdb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// sendWithRetry sends a request, retrying it according to the client's
+// retry policy when the method makes that safe. Every attempt carries the
+// same headers.
+func (c *Client) sendWithRetry(ctx context.Context, method, endpoint string, body []byte, header http.Header) (*response, error) {
+	retryable := isSafeMethod(method)
+	if !retryable && c.idempotencyKeys {
+		header.Set("Idempotency-Key", newIdempotencyKey())
+		retryable = true
+	}
+
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		var resp *response
+		var err error
+		if method == "GET" && c.hedgeDelay > 0 {
+			resp, err = c.sendHedged(ctx, method, endpoint, header)
+		} else {
+			resp, err = c.send(ctx, method, endpoint, body, header)
+		}
+
+		if !retryable || attempt >= c.maxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// isSafeMethod reports whether repeating a request with this method cannot
+// change server state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating: network
+// errors that were not caused by the caller's context, and gateway-style
+// server errors.
+func shouldRetry(ctx context.Context, resp *response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
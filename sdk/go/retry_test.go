@@ -0,0 +1,86 @@
+package barqgraphdb
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// keyRecorder records the Idempotency-Key header of each request.
+type keyRecorder struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (k *keyRecorder) add(key string) int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys = append(k.keys, key)
+	return len(k.keys)
+}
+
+func (k *keyRecorder) all() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string(nil), k.keys...)
+}
+
+// flakyServer drops the connection on the first request and then answers
+// normally, recording each request's Idempotency-Key header.
+func flakyServer(t *testing.T, opts ...Option) (*Client, *keyRecorder) {
+	rec := &keyRecorder{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if rec.add(r.Header.Get("Idempotency-Key")) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatalf("hijack failed: %v", err)
+			}
+			conn.Close()
+			return
+		}
+		w.Write([]byte(`{"status":"healthy"}`))
+	}, opts...)
+	return client, rec
+}
+
+func TestRetryGETOnNetworkError(t *testing.T) {
+	client, rec := flakyServer(t, WithRetry(2, time.Millisecond))
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Expected GET to succeed after retry, got %v", err)
+	}
+	if keys := rec.all(); len(keys) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(keys))
+	}
+}
+
+func TestNoRetryPOSTWithoutIdempotencyKey(t *testing.T) {
+	client, rec := flakyServer(t, WithRetry(2, time.Millisecond))
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err == nil {
+		t.Fatal("Expected POST to fail without retry")
+	}
+	keys := rec.all()
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", len(keys))
+	}
+	if keys[0] != "" {
+		t.Errorf("Expected no Idempotency-Key header, got %q", keys[0])
+	}
+}
+
+func TestRetryPOSTWithIdempotencyKey(t *testing.T) {
+	client, rec := flakyServer(t, WithRetry(2, time.Millisecond), WithIdempotencyKeys())
+
+	if err := client.CreateNode(&Node{ID: 1, Label: "User"}); err != nil {
+		t.Fatalf("Expected keyed POST to succeed after retry, got %v", err)
+	}
+	keys := rec.all()
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("Expected the same Idempotency-Key on both attempts, got %q", keys)
+	}
+}
@@ -0,0 +1,191 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// openStream sends a request to an endpoint that streams its results and
+// returns the response with its body unread. Error responses are read and
+// returned as an *Error. The caller must close the body.
+//
+// The client's request timeout would cut a long-lived stream short, so it
+// only bounds the wait for the response headers (see
+// WithStreamConnectTimeout). After that the stream lives until it ends, the
+// body is closed, or ctx is cancelled.
+func (c *Client) openStream(ctx context.Context, method, endpoint string, body interface{}, header http.Header) (*http.Response, error) {
+	var reqBody []byte
+	if body != nil {
+		jsonBytes, err := c.encodeBody(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = jsonBytes
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	if c.requestIDGenerator != nil {
+		header.Set("X-Request-ID", c.requestIDGenerator())
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	connectTimeout := c.streamConnectTimeout
+	if connectTimeout == 0 {
+		connectTimeout = c.timeoutFor(endpoint)
+	}
+	var timer *time.Timer
+	fired := make(chan struct{})
+	if connectTimeout > 0 {
+		timer = time.AfterFunc(connectTimeout, func() {
+			cancel()
+			close(fired)
+		})
+	}
+
+	resp, err := c.roundTrip(ctx, method, endpoint, reqBody, header)
+	if timer != nil && !timer.Stop() {
+		<-fired
+		if err == nil {
+			resp.Body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("stream did not respond within %v: %w", connectTimeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer cancel()
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		err = parseError(resp.StatusCode, respBody)
+		var apiErr *Error
+		if errors.As(err, &apiErr) {
+			apiErr.Method = method
+			apiErr.Endpoint = endpoint
+			apiErr.RequestID = header.Get("X-Request-ID")
+			if echoed := resp.Header.Get("X-Request-ID"); echoed != "" {
+				apiErr.RequestID = echoed
+			}
+		}
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a stream's context when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// decodeStream reads newline-delimited JSON values from r and passes each
+// one to emit until r is exhausted or emit fails. A panic while decoding or
+// emitting is recovered and returned as an error, so a malformed chunk from
+// the server can never crash the consumer's goroutine.
+func decodeStream(r io.Reader, emit func(json.RawMessage) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("barqgraphdb: recovered from panic while decoding stream: %v", p)
+		}
+	}()
+
+	dec := json.NewDecoder(r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to decode stream: %w", err)
+		}
+		if err := emit(raw); err != nil {
+			return err
+		}
+	}
+}
+
+// HybridQueryStream performs a hybrid query and delivers results as the
+// server produces them. The results channel is closed when the stream ends;
+// at most one error is sent on the error channel, which is closed after the
+// results channel. Cancelling ctx stops the stream; the results already
+// delivered stand, and the error then wraps ctx.Err().
+func (c *Client) HybridQueryStream(ctx context.Context, start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) (<-chan HybridResult, <-chan error) {
+	results := make(chan HybridResult)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(results)
+
+		if err := c.requireFeature(FeatureStreaming); err != nil {
+			errs <- err
+			return
+		}
+
+		req, err := c.newHybridQueryRequest(start, queryEmbedding, maxHops, k, params)
+		if err != nil {
+			errs <- err
+			return
+		}
+		resp, err := c.openStream(ctx, "POST", exactParam("/query/hybrid?stream=true", req.Exact), req, nil)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		sent := 0
+		err = decodeStream(resp.Body, func(raw json.RawMessage) error {
+			var r HybridResult
+			if err := c.decodeBody(raw, &r); err != nil {
+				return err
+			}
+			select {
+			case results <- r:
+				sent++
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("stream interrupted after %d results: %w", sent, ctx.Err())
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return results, errs
+}
+
+// HybridQueryStreamCollect runs HybridQueryStream and gathers its results
+// into a slice. If ctx's deadline passes mid-stream, the results received so
+// far are returned together with an error wrapping
+// context.DeadlineExceeded, so latency-sensitive callers can settle for the
+// best matches found in time.
+func (c *Client) HybridQueryStreamCollect(ctx context.Context, start uint64, queryEmbedding []float32, maxHops, k int, params HybridParams) ([]HybridResult, error) {
+	results, errs := c.HybridQueryStream(ctx, start, queryEmbedding, maxHops, k, params)
+	collected := []HybridResult{}
+	for r := range results {
+		collected = append(collected, r)
+	}
+	return collected, <-errs
+}
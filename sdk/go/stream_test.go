@@ -0,0 +1,188 @@
+package barqgraphdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamingServer advertises streaming support and serves body as the
+// hybrid query stream.
+func streamingServer(t *testing.T, body string) *Client {
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["streaming"]}`))
+		case "/query/hybrid":
+			if r.URL.Query().Get("stream") != "true" {
+				t.Errorf("Expected stream=true, got %s", r.URL)
+			}
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Write([]byte(body))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+}
+
+func TestHybridQueryStream(t *testing.T) {
+	client := streamingServer(t, "{\"id\":2,\"score\":0.9}\n{\"id\":3,\"score\":0.7}\n")
+
+	results, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	var ids []uint64
+	for r := range results {
+		ids = append(ids, r.ID)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("HybridQueryStream failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Errorf("Expected results [2 3], got %v", ids)
+	}
+}
+
+func TestHybridQueryStreamRequestID(t *testing.T) {
+	var sent []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			w.Write([]byte(`{"features":["streaming"]}`))
+			return
+		}
+		sent = append(sent, r.Header.Get("X-Request-ID"))
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "busy"})
+	}, WithRequestIDGenerator(func() string { return "req-1" }))
+
+	_, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	err := <-errs
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "req-1" {
+		t.Errorf("Expected an *Error with request ID req-1, got %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "req-1" {
+		t.Errorf("Expected X-Request-ID req-1 on the stream request, got %v", sent)
+	}
+}
+
+func TestHybridQueryStreamMalformedChunk(t *testing.T) {
+	client := streamingServer(t, "{\"id\":2,\"score\":0.9}\n{\"id\":3,\"sco\n")
+
+	results, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 result before the malformed chunk, got %d", count)
+	}
+	if err := <-errs; err == nil {
+		t.Error("Expected an error for the malformed chunk")
+	}
+	if _, ok := <-errs; ok {
+		t.Error("Expected the error channel to be closed")
+	}
+}
+
+func TestDecodeStreamRecoversPanic(t *testing.T) {
+	err := decodeStream(strings.NewReader(`{"id":1}`), func(json.RawMessage) error {
+		var m map[string]int
+		m["boom"] = 1 // assignment to nil map panics
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic") {
+		t.Errorf("Expected recovered panic as error, got %v", err)
+	}
+}
+
+func TestHybridQueryStreamOutlivesTimeout(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["streaming"]}`))
+		case "/query/hybrid":
+			w.Write([]byte("{\"id\":2,\"score\":0.9}\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(150 * time.Millisecond)
+			w.Write([]byte("{\"id\":3,\"score\":0.7}\n"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	client.timeout = 50 * time.Millisecond
+
+	results, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	count := 0
+	for range results {
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("HybridQueryStream failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+}
+
+func TestHybridQueryStreamConnectTimeout(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["streaming"]}`))
+		case "/query/hybrid":
+			time.Sleep(150 * time.Millisecond)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}, WithStreamConnectTimeout(20*time.Millisecond))
+
+	results, errs := client.HybridQueryStream(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	for range results {
+	}
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a connect timeout, got %v", err)
+	}
+}
+
+func TestHybridQueryStreamCollectDeadline(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"features":["streaming"]}`))
+		case "/query/hybrid":
+			w.Write([]byte("{\"id\":2,\"score\":0.9}\n{\"id\":3,\"score\":0.7}\n"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			w.Write([]byte("{\"id\":4,\"score\":0.5}\n"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	results, err := client.HybridQueryStreamCollect(ctx, 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected an error wrapping DeadlineExceeded, got %v", err)
+	}
+	if len(results) != 2 || results[0].ID != 2 || results[1].ID != 3 {
+		t.Errorf("Expected partial results [2 3], got %+v", results)
+	}
+}
+
+func TestHybridQueryStreamCollect(t *testing.T) {
+	client := streamingServer(t, "{\"id\":2,\"score\":0.9}\n{\"id\":3,\"score\":0.7}\n")
+
+	results, err := client.HybridQueryStreamCollect(context.Background(), 1, []float32{0.1}, 2, 5, DefaultHybridParams())
+	if err != nil {
+		t.Fatalf("HybridQueryStreamCollect failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 results, got %+v", results)
+	}
+}
@@ -0,0 +1,122 @@
+package barqgraphdb
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// NodeBatchWriter buffers nodes and creates them with CreateNodes in
+// batches. A batch is sent as soon as the buffer holds batchSize nodes, or
+// when flushInterval has elapsed since the last flush, whichever comes first.
+// It is safe for concurrent use.
+type NodeBatchWriter struct {
+	client    *Client
+	batchSize int
+
+	mu     sync.Mutex
+	buf    []Node
+	err    error
+	closed bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewNodeBatchWriter creates a writer that flushes every batchSize nodes and
+// every flushInterval. A zero flushInterval disables time-based flushing.
+// The writer must be closed to send any remaining nodes.
+func (c *Client) NewNodeBatchWriter(batchSize int, flushInterval time.Duration) *NodeBatchWriter {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	w := &NodeBatchWriter{
+		client:    c,
+		batchSize: batchSize,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go w.run(flushInterval)
+	return w
+}
+
+func (w *NodeBatchWriter) run(interval time.Duration) {
+	defer close(w.done)
+	if interval <= 0 {
+		<-w.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if err := w.flushLocked(); err != nil && w.err == nil {
+				w.err = err
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Add buffers a node, flushing if the buffer is full. It returns any error
+// from a flush triggered by this call or from an earlier background flush;
+// the node is buffered either way.
+func (w *NodeBatchWriter) Add(node Node) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.buf = append(w.buf, node)
+	var err error
+	if len(w.buf) >= w.batchSize {
+		err = w.flushLocked()
+	}
+	return errors.Join(w.takeErrLocked(), err)
+}
+
+// Flush sends any buffered nodes immediately. It also returns the error of
+// an earlier background flush, if any.
+func (w *NodeBatchWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.flushLocked()
+	return errors.Join(w.takeErrLocked(), err)
+}
+
+// Close stops background flushing and sends any remaining nodes.
+func (w *NodeBatchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+	return w.Flush()
+}
+
+func (w *NodeBatchWriter) flushLocked() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	batch := w.buf
+	w.buf = nil
+	return w.client.CreateNodes(batch)
+}
+
+func (w *NodeBatchWriter) takeErrLocked() error {
+	err := w.err
+	w.err = nil
+	return err
+}
@@ -0,0 +1,179 @@
+package barqgraphdb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder is a mock /nodes/batch endpoint that records batch sizes
+// and the nodes received.
+type batchRecorder struct {
+	mu       sync.Mutex
+	batches  []int
+	received []Node
+}
+
+func (b *batchRecorder) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/nodes/batch" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Nodes []Node `json:"nodes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		b.mu.Lock()
+		b.batches = append(b.batches, len(body.Nodes))
+		b.received = append(b.received, body.Nodes...)
+		b.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (b *batchRecorder) sizes() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]int(nil), b.batches...)
+}
+
+func (b *batchRecorder) nodes() []Node {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Node(nil), b.received...)
+}
+
+func TestNodeBatchWriterSizeFlush(t *testing.T) {
+	rec := &batchRecorder{}
+	client := newTestClient(t, rec.handler(t))
+	w := client.NewNodeBatchWriter(3, 0)
+
+	for i := uint64(1); i <= 7; i++ {
+		if err := w.Add(Node{ID: i, Label: "Item"}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if got := rec.sizes(); len(got) != 2 || got[0] != 3 || got[1] != 3 {
+		t.Errorf("Expected two full batches before Close, got %v", got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := rec.sizes(); len(got) != 3 || got[2] != 1 {
+		t.Errorf("Expected final flush of 1 node on Close, got %v", got)
+	}
+	if err := w.Add(Node{ID: 8}); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("Expected ErrWriterClosed, got %v", err)
+	}
+}
+
+func TestNodeBatchWriterIntervalFlush(t *testing.T) {
+	rec := &batchRecorder{}
+	client := newTestClient(t, rec.handler(t))
+	w := client.NewNodeBatchWriter(100, 20*time.Millisecond)
+	defer w.Close()
+
+	w.Add(Node{ID: 1, Label: "Item"})
+	w.Add(Node{ID: 2, Label: "Item"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := rec.sizes(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected one timed flush of 2 nodes, got %v", got)
+	}
+}
+
+func TestNodeBatchWriterAfterFailedFlush(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	var received []uint64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Nodes []Node `json:"nodes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "boom"})
+			return
+		}
+		for _, n := range body.Nodes {
+			received = append(received, n.ID)
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	w := client.NewNodeBatchWriter(100, 10*time.Millisecond)
+
+	if err := w.Add(Node{ID: 1}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	// Wait for the timed flush of node 1 to fail.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		w.mu.Lock()
+		failed := w.err != nil
+		w.mu.Unlock()
+		if failed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the background flush to fail")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var apiErr *Error
+	if err := w.Add(Node{ID: 2}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected the earlier flush error from Add, got %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0] != 2 {
+		t.Errorf("Expected node 2 to reach the server, got %v", received)
+	}
+}
+
+func TestNodeBatchWriterFlushReportsEarlierError(t *testing.T) {
+	var mu sync.Mutex
+	var received []uint64
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Nodes []Node `json:"nodes"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		for _, n := range body.Nodes {
+			received = append(received, n.ID)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	w := client.NewNodeBatchWriter(100, 0)
+	defer w.Close()
+
+	earlier := errors.New("earlier flush failed")
+	w.Add(Node{ID: 3})
+	w.mu.Lock()
+	w.err = earlier
+	w.mu.Unlock()
+
+	if err := w.Flush(); !errors.Is(err, earlier) {
+		t.Errorf("Expected the earlier error from Flush, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0] != 3 {
+		t.Errorf("Expected Flush to send the buffered node anyway, got %v", received)
+	}
+}